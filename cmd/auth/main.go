@@ -2,59 +2,235 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/AndreyChufelin/movies-auth/internal/apikey"
+	"github.com/AndreyChufelin/movies-auth/internal/audit"
+	"github.com/AndreyChufelin/movies-auth/internal/certs"
 	"github.com/AndreyChufelin/movies-auth/internal/config"
+	"github.com/AndreyChufelin/movies-auth/internal/diagnostics"
+	"github.com/AndreyChufelin/movies-auth/internal/health"
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
 	"github.com/AndreyChufelin/movies-auth/internal/mailer"
+	"github.com/AndreyChufelin/movies-auth/internal/mailqueue"
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+	"github.com/AndreyChufelin/movies-auth/internal/password"
+	"github.com/AndreyChufelin/movies-auth/internal/revocation"
 	grpcserver "github.com/AndreyChufelin/movies-auth/internal/server/grpc"
 	"github.com/AndreyChufelin/movies-auth/internal/storage/postgres"
+	"github.com/AndreyChufelin/movies-auth/internal/tracing"
+	"github.com/AndreyChufelin/movies-auth/internal/webhook"
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 )
 
 func main() {
+	configPath := flag.String("config", "configs/config-auth.toml", "path to the TOML config file")
+	flag.Parse()
+
 	ctx, cancel := signal.NotifyContext(context.Background(),
-		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
 	logg := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	config, err := config.LoadConfig("configs/config-auth.toml")
+	config, err := config.LoadConfig(*configPath)
 	if err != nil {
 		logg.Error(
 			"failed to load config",
+			"path", *configPath,
 			"error", err,
 		)
-		cancel()
+		os.Exit(1)
+	}
+
+	logg, levelVar, err := logging.New(os.Stdout, config.Log.Level, config.Log.Format)
+	if err != nil {
+		slog.Error("failed to create logger", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logg)
+	logg.Info("config loaded", "path", *configPath, "config", config)
+
+	shutdownTracing, err := tracing.Setup(ctx, config.Tracing)
+	if err != nil {
+		logg.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
 	}
 
 	logg.Info("connecting to database")
-	storage := postgres.NewStorage(
-		config.DB.Host,
-		config.DB.Port,
-		config.DB.User,
-		config.DB.Password,
-		config.DB.Name,
-	)
+	storage := postgres.NewStorage(config.DB)
 	err = storage.Connect(ctx)
 	if err != nil {
-		logg.Error(
-			"falied to create connection with database",
-			"error", err,
-		)
-		cancel()
+		logg.Error("failed to create connection with database", "error", err)
+		os.Exit(1)
+	}
+
+	transport, err := newMailTransport(config.Mailer)
+	if err != nil {
+		logg.Error("failed to create mail transport", "error", err)
+		os.Exit(1)
+	}
+	branding := mailer.Branding{
+		ProductName:  config.Mailer.Branding.ProductName,
+		SupportEmail: config.Mailer.Branding.SupportEmail,
+		URLs:         config.Mailer.Branding.URLs,
+	}
+	mailer, err := mailer.New(transport, storage, config.Mailer.Sender, config.Mailer.TemplatesDir, branding)
+	if err != nil {
+		logg.Error("failed to load email templates", "error", err)
+		os.Exit(1)
 	}
 
-	mailer := mailer.New(
-		config.Mailer.Host,
-		config.Mailer.Port,
-		config.Mailer.Username,
-		config.Mailer.Password,
-		config.Mailer.Sender,
+	revocations := revocation.NewHub()
+	go func() {
+		for {
+			err := storage.ListenRevocations(ctx, revocations.Publish)
+			if ctx.Err() != nil {
+				return
+			}
+			logg.Error("revocation listener stopped, reconnecting", "error", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+
+	webhooks := webhook.New(storage, logg, config.Webhooks)
+	webhooksDone := make(chan struct{})
+	go func() {
+		webhooks.Run(ctx)
+		close(webhooksDone)
+	}()
+
+	hasher, err := newPasswordHasher(config.Password)
+	if err != nil {
+		logg.Error("failed to create password hasher", "error", err)
+		os.Exit(1)
+	}
+
+	passwords, err := newPasswordPolicy(config.Password)
+	if err != nil {
+		logg.Error("failed to create password policy", "error", err)
+		os.Exit(1)
+	}
+
+	emails := mailqueue.New(storage, mailer, logg, config.Mailer)
+	emailsDone := make(chan struct{})
+	go func() {
+		emails.Run(ctx)
+		close(emailsDone)
+	}()
+
+	// Registered even without the metrics listener, since the debug
+	// listener publishes the same values through expvar.
+	metrics.Registry.MustRegister(
+		metrics.NewPoolCollector(storage.PoolStat),
+		metrics.NewOutboxCollector(storage.OutboxBacklog),
 	)
 
-	server := grpcserver.NewGRPC(logg, storage, mailer, "50051")
+	var httpServers []*http.Server
+	if config.Metrics.Enabled {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		httpServers = append(httpServers, serveHTTP(logg, "metrics", config.Metrics.Address, mux))
+	}
+
+	checks := []health.Check{
+		{Name: "database", Critical: true, Probe: storage.Ping},
+		{Name: "migrations", Critical: true, Probe: storage.CheckSchema},
+	}
+	if pinger, ok := transport.(interface{ Ping(context.Context) error }); ok {
+		checks = append(checks, health.Check{Name: "smtp", Probe: pinger.Ping})
+	}
+	healthChecker := health.New(
+		logg,
+		config.Health.Interval,
+		[]string{pbuser.UserService_ServiceDesc.ServiceName},
+		checks...,
+	)
+	go healthChecker.Run(ctx)
+	if config.Health.Address != "" {
+		httpServers = append(httpServers, serveHTTP(logg, "health", config.Health.Address, healthChecker.Handler()))
+	}
+
+	reloads := &reloader{
+		path:      *configPath,
+		logger:    logg,
+		levelVar:  levelVar,
+		mailer:    mailer,
+		transport: transport,
+		current:   config,
+	}
+
+	if config.Debug.Enabled {
+		handler := diagnostics.Handler(func() any { return reloads.Config().Redacted() })
+		httpServers = append(httpServers, serveHTTP(logg, "debug", config.Debug.Address, handler))
+	}
+
+	serverOpts := grpcServerOptions(config.Server)
+	tlsOpts, err := tlsServerOptions(ctx, logg, config.TLS)
+	if err != nil {
+		logg.Error("failed to configure tls", "error", err)
+		os.Exit(1)
+	}
+	serverOpts = append(serverOpts, tlsOpts...)
+
+	for _, method := range config.APIKeys.Protected {
+		if !grpcserver.IsServiceMethod(method) {
+			logg.Error("unknown method in api_keys.protected", "method", method)
+			os.Exit(1)
+		}
+	}
+	apiKeys := apikey.New(storage, config.APIKeys.Protected)
+	serverOpts = append(serverOpts,
+		grpc.ChainUnaryInterceptor(apiKeys.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(apiKeys.StreamServerInterceptor()),
+	)
+
+	server := grpcserver.NewGRPC(
+		logg,
+		storage,
+		revocations,
+		audit.New(storage, logg),
+		webhooks,
+		passwords,
+		hasher,
+		healthChecker,
+		tokenTTLs(config.Tokens),
+		config.Server.Address,
+		serverOpts...,
+	)
+	if config.Debug.Reflection {
+		server.EnableReflection()
+	}
+
+	reloads.server = server
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				reloads.Reload()
+			}
+		}
+	}()
 	go func() {
 		if err := server.Start(); err != nil {
 			logg.Error("failed to start grpc server", "err", err)
@@ -64,12 +240,220 @@ func main() {
 
 	<-ctx.Done()
 
-	ctxStop, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctxStop, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout)
 	defer cancel()
 
-	storage.Close(ctx)
-
 	if err := server.Stop(ctxStop); err != nil {
 		logg.Error("failed to stop grpc server", "err", err)
 	}
+	for _, httpServer := range httpServers {
+		if err := httpServer.Shutdown(ctxStop); err != nil {
+			logg.Error("failed to stop http server", "addr", httpServer.Addr, "error", err)
+		}
+	}
+
+	select {
+	case <-webhooksDone:
+	case <-ctxStop.Done():
+		logg.Warn("webhook workers did not stop in time")
+	}
+	select {
+	case <-emailsDone:
+	case <-ctxStop.Done():
+		logg.Warn("email workers did not stop in time")
+	}
+
+	if closer, ok := transport.(io.Closer); ok {
+		closer.Close()
+	}
+	if passwords.Breached != nil {
+		passwords.Breached.Close()
+	}
+
+	storage.Close(ctx)
+
+	if err := shutdownTracing(ctxStop); err != nil {
+		logg.Error("failed to flush traces", "error", err)
+	}
+}
+
+func serveHTTP(logg *slog.Logger, name, addr string, handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logg.Info(name+" server started", "addr", addr)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logg.Error("failed to start "+name+" server", "error", err)
+		}
+	}()
+
+	return server
+}
+
+func grpcServerOptions(conf config.ServerConf) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(conf.MaxMessageSize),
+		grpc.MaxSendMsgSize(conf.MaxMessageSize),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:              conf.Keepalive.Time,
+			Timeout:           conf.Keepalive.Timeout,
+			MaxConnectionIdle: conf.Keepalive.MaxConnectionIdle,
+			MaxConnectionAge:  conf.Keepalive.MaxConnectionAge,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             conf.Keepalive.MinTime,
+			PermitWithoutStream: conf.Keepalive.PermitWithoutStream,
+		}),
+	}
+}
+
+// tlsServerOptions returns the credentials for the gRPC listener and, when
+// client certificates are verified, the interceptors enforcing per-method
+// SAN rules. The certificate is reloaded in the background until ctx is done.
+func tlsServerOptions(ctx context.Context, logg *slog.Logger, conf config.TLSConf) ([]grpc.ServerOption, error) {
+	if !conf.Enabled {
+		return nil, nil
+	}
+
+	rules := make(map[string][]string, len(conf.Authorize))
+	for _, rule := range conf.Authorize {
+		if !grpcserver.IsServiceMethod(rule.Method) {
+			return nil, fmt.Errorf("unknown method in tls.authorize: %q", rule.Method)
+		}
+		rules[rule.Method] = append(rules[rule.Method], rule.SANs...)
+	}
+
+	reloader, err := certs.NewReloader(logg, conf.CertFile, conf.KeyFile, conf.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.Run(ctx, conf.ReloadInterval)
+
+	opts := []grpc.ServerOption{grpc.Creds(credentials.NewTLS(reloader.TLSConfig()))}
+	if len(rules) > 0 {
+		authorizer := certs.NewAuthorizer(rules)
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(authorizer.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(authorizer.StreamServerInterceptor()),
+		)
+	}
+
+	return opts, nil
+}
+
+func newPasswordPolicy(conf config.PasswordConf) (*password.Policy, error) {
+	policy := &password.Policy{
+		MinLength:      conf.MinLength,
+		MaxLength:      conf.MaxLength,
+		RequireLower:   conf.RequireLower,
+		RequireUpper:   conf.RequireUpper,
+		RequireDigit:   conf.RequireDigit,
+		RequireSymbol:  conf.RequireSymbol,
+		ForbidPersonal: conf.ForbidPersonal,
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = 8
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = 128
+	}
+	if conf.Hashing.Algorithm == "bcrypt" && policy.MaxLength > password.BcryptMaxLength {
+		policy.MaxLength = password.BcryptMaxLength
+	}
+
+	if conf.BreachedFile != "" {
+		corpus, err := password.LoadCorpus(conf.BreachedFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breached = corpus
+	}
+
+	return policy, nil
+}
+
+// newPasswordHasher hashes with the configured algorithm and keeps the other
+// one for verifying old hashes.
+func newPasswordHasher(passwordConf config.PasswordConf) (*password.Hasher, error) {
+	conf := passwordConf.Hashing
+	params := password.Argon2Params{
+		Memory:      conf.Argon2id.Memory,
+		Iterations:  conf.Argon2id.Iterations,
+		Parallelism: conf.Argon2id.Parallelism,
+		SaltLength:  conf.Argon2id.SaltLength,
+		KeyLength:   conf.Argon2id.KeyLength,
+	}
+	if params == (password.Argon2Params{}) {
+		params = password.DefaultArgon2Params
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 ||
+		params.SaltLength < 8 || params.KeyLength < 16 {
+		return nil, fmt.Errorf("invalid argon2id parameters %+v", params)
+	}
+	argon2id := password.Argon2id{Params: params}
+
+	cost := conf.BcryptCost
+	if cost == 0 {
+		cost = 12
+	}
+	bcrypt := password.Bcrypt{Cost: cost}
+
+	var hasher *password.Hasher
+	switch conf.Algorithm {
+	case "", "argon2id":
+		hasher = password.NewHasher(argon2id, bcrypt)
+	case "bcrypt":
+		hasher = password.NewHasher(bcrypt, argon2id)
+	default:
+		return nil, fmt.Errorf("unknown password hashing algorithm %q", conf.Algorithm)
+	}
+
+	pepper, err := loadPepper(passwordConf.Pepper)
+	if err != nil {
+		return nil, err
+	}
+	if pepper != nil {
+		hasher = hasher.WithPepper(pepper)
+	}
+
+	return hasher, nil
+}
+
+func loadPepper(conf config.PepperConf) (*password.Pepper, error) {
+	if conf.Current == "" {
+		return nil, nil
+	}
+
+	keys, err := password.ParsePepperKeys(conf.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	pepper := &password.Pepper{Current: conf.Current, Keys: keys}
+	err = pepper.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return pepper, nil
+}
+
+func newMailTransport(conf config.MailerConf) (mailer.Transport, error) {
+	switch conf.Transport {
+	case "", "smtp":
+		return mailer.NewSMTPTransport(
+			conf.Host, conf.Port, conf.Username, conf.Password, conf.TLS, conf.IdleTimeout, conf.Timeout,
+		)
+	case "file":
+		return mailer.NewDirTransport(conf.Dir)
+	case "memory":
+		return mailer.NewMemoryTransport(), nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", conf.Transport)
+	}
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/AndreyChufelin/movies-auth/internal/config"
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/mailer"
+	grpcserver "github.com/AndreyChufelin/movies-auth/internal/server/grpc"
+)
+
+// reloadable lists the settings applied on SIGHUP. Everything else, such as
+// listen addresses or the database, needs a restart. There is no rate
+// limiting in the service yet, so there are no limits to reload either.
+var reloadable = []string{
+	"log.level",
+	"tokens.activation_ttl",
+	"tokens.authentication_ttl",
+	"mailer.username",
+	"mailer.password",
+	"mailer.password_file",
+	"mailer.templates_dir",
+}
+
+// reloader re-reads the config file and applies the reloadable settings
+// without touching open connections. It tracks the effective config, so
+// ignored changes are reported again on the next reload until restarted.
+type reloader struct {
+	path      string
+	logger    *slog.Logger
+	levelVar  *slog.LevelVar
+	server    *grpcserver.Server
+	mailer    *mailer.Mailer
+	transport mailer.Transport
+
+	mu      sync.Mutex
+	current config.Config
+}
+
+func (r *reloader) Config() config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload applies the new config all at once or not at all: an invalid file
+// or broken templates keep the previous config in place.
+func (r *reloader) Reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logger.Info("reloading config", "path", r.path)
+	next, err := config.LoadConfig(r.path)
+	if err != nil {
+		r.logger.Error("failed to reload config, keeping previous one", "error", err)
+		return
+	}
+
+	// Templates are re-read on every reload, so edited overrides are picked
+	// up even when the directory stays the same.
+	err = r.mailer.SetTemplatesDir(next.Mailer.TemplatesDir)
+	if err != nil {
+		r.logger.Error("failed to reload email templates, keeping previous config", "error", err)
+		return
+	}
+
+	changes := config.Diff(r.current, next)
+	for _, change := range changes {
+		if !slices.Contains(reloadable, change.Key) {
+			r.logger.Warn("config change needs a restart, ignored",
+				"key", change.Key, "old", change.Old, "new", change.New)
+			continue
+		}
+		r.logger.Info("config changed", "key", change.Key, "old", change.Old, "new", change.New)
+	}
+
+	applied := r.current
+
+	// LoadConfig has validated the level already.
+	_ = logging.SetLevel(r.levelVar, next.Log.Level)
+	applied.Log.Level = next.Log.Level
+
+	r.server.SetTokenTTLs(tokenTTLs(next.Tokens))
+	applied.Tokens = next.Tokens
+
+	if smtp, ok := r.transport.(*mailer.SMTPTransport); ok {
+		smtp.SetCredentials(next.Mailer.Username, next.Mailer.Password)
+	}
+	applied.Mailer.Username = next.Mailer.Username
+	applied.Mailer.Password = next.Mailer.Password
+	applied.Mailer.PasswordFile = next.Mailer.PasswordFile
+	applied.Mailer.TemplatesDir = next.Mailer.TemplatesDir
+
+	r.current = applied
+	r.logger.Info("config reloaded", "changes", len(changes))
+}
+
+func tokenTTLs(conf config.TokensConf) grpcserver.TokenTTLs {
+	return grpcserver.TokenTTLs{
+		Activation:     conf.ActivationTTL,
+		Authentication: conf.AuthenticationTTL,
+	}
+}
@@ -0,0 +1,131 @@
+// Package apikey authenticates other services by the API key they send in
+// the x-api-key metadata header.
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/rpcmethod"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const MetadataKey = "x-api-key"
+
+type Store interface {
+	GetAPIKey(ctx context.Context, plaintext string) (*storage.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int64) error
+}
+
+// Authenticator requires a valid key, allowed to call the method, on every
+// protected method. A key sent to any other method is still checked, so a
+// revoked or mistyped key fails loudly instead of being ignored. Health and
+// reflection calls are never checked, so probes keep working with "*".
+type Authenticator struct {
+	store     Store
+	protected []string
+}
+
+// New protects the given methods, named in full, by their short name or as
+// "*" for all of UserService.
+func New(store Store, protected []string) *Authenticator {
+	return &Authenticator{store: store, protected: protected}
+}
+
+type contextKey struct{}
+
+// FromContext returns the key the call was authenticated with, if any.
+func FromContext(ctx context.Context) (*storage.APIKey, bool) {
+	key, ok := ctx.Value(contextKey{}).(*storage.APIKey)
+	return key, ok
+}
+
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if rpcmethod.IsExempt(method) {
+		return ctx, nil
+	}
+
+	logg := logging.FromContext(ctx)
+
+	var plaintext string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(MetadataKey); len(values) > 0 {
+		plaintext = values[0]
+	}
+
+	if plaintext == "" {
+		if a.isProtected(method) {
+			return nil, status.Error(codes.Unauthenticated, "api key required")
+		}
+		return ctx, nil
+	}
+
+	key, err := a.store.GetAPIKey(ctx, plaintext)
+	if err != nil {
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "invalid api key")
+		}
+		logg.Error("failed to get api key", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if key.IsExpired(time.Now()) {
+		return nil, status.Error(codes.Unauthenticated, "api key expired")
+	}
+	if !key.Allows(method) {
+		logg.Warn("api key not allowed to call method", "key_id", key.ID, "key_name", key.Name)
+		return nil, status.Error(codes.PermissionDenied, "api key not allowed to call this method")
+	}
+
+	if key.UseIsStale(time.Now()) {
+		err = a.store.TouchAPIKey(ctx, key.ID)
+		if err != nil {
+			logg.Warn("failed to record api key use", "key_id", key.ID, "error", err)
+		}
+	}
+
+	return context.WithValue(ctx, contextKey{}, key), nil
+}
+
+func (a *Authenticator) isProtected(method string) bool {
+	return rpcmethod.MatchesAny(a.protected, method)
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,136 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeStore struct {
+	keys    map[string]*storage.APIKey
+	touched []int64
+}
+
+func (s *fakeStore) GetAPIKey(_ context.Context, plaintext string) (*storage.APIKey, error) {
+	key, ok := s.keys[plaintext]
+	if !ok {
+		return nil, storage.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *fakeStore) TouchAPIKey(_ context.Context, id int64) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/user.UserService/WatchRevocations"
+
+	now := time.Now()
+	recent := now.Add(-10 * time.Second)
+	stale := now.Add(-2 * storage.APIKeyUseResolution)
+	expired := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		key     string
+		code    codes.Code
+		touched bool
+	}{
+		{name: "missing key on protected method", code: codes.Unauthenticated},
+		{name: "unknown key", key: "unknown", code: codes.Unauthenticated},
+		{name: "expired key", key: "expired", code: codes.Unauthenticated},
+		{name: "key for another method", key: "other", code: codes.PermissionDenied},
+		{name: "never used key is touched", key: "new", code: codes.OK, touched: true},
+		{name: "stale key is touched", key: "stale", code: codes.OK, touched: true},
+		{name: "recently used key is not touched", key: "recent", code: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{keys: map[string]*storage.APIKey{
+				"expired": {ID: 1, Methods: []string{"*"}, Expiry: &expired},
+				"other":   {ID: 2, Methods: []string{"Authorize"}},
+				"new":     {ID: 3, Methods: []string{"WatchRevocations"}},
+				"stale":   {ID: 4, Methods: []string{method}, LastUsedAt: &stale},
+				"recent":  {ID: 5, Methods: []string{"*"}, LastUsedAt: &recent},
+			}}
+			interceptor := New(store, []string{"WatchRevocations"}).UnaryServerInterceptor()
+
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, tt.key))
+			}
+
+			var authenticated *storage.APIKey
+			handler := func(ctx context.Context, _ any) (any, error) {
+				authenticated, _ = FromContext(ctx)
+				return nil, nil
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+			if status.Code(err) != tt.code {
+				t.Fatalf("code = %v, want %v (error %v)", status.Code(err), tt.code, err)
+			}
+			if tt.code == codes.OK && authenticated != store.keys[tt.key] {
+				t.Errorf("FromContext() = %v, want key %q", authenticated, tt.key)
+			}
+			if touched := len(store.touched) > 0; touched != tt.touched {
+				t.Errorf("touched = %v, want %v", touched, tt.touched)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorProtectsOnlyUserService(t *testing.T) {
+	store := &fakeStore{keys: map[string]*storage.APIKey{
+		"revoke": {ID: 1, Methods: []string{"WatchRevocations"}},
+	}}
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+
+	tests := []struct {
+		name      string
+		protected []string
+		method    string
+		key       string
+		code      codes.Code
+	}{
+		{name: "all methods", protected: []string{"*"}, method: "/user.UserService/Authorize", code: codes.Unauthenticated},
+		{name: "health with all methods", protected: []string{"*"}, method: "/grpc.health.v1.Health/Check"},
+		{
+			name:      "reflection with all methods",
+			protected: []string{"*"},
+			method:    "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+		},
+		{name: "short name of another service", protected: []string{"Check"}, method: "/grpc.health.v1.Health/Check"},
+		{name: "short name", protected: []string{"Check"}, method: "/user.UserService/Check", code: codes.Unauthenticated},
+		{
+			name:      "key sent to health",
+			protected: []string{"*"},
+			method:    "/grpc.health.v1.Health/Check",
+			key:       "revoke",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, tt.key))
+			}
+
+			interceptor := New(store, tt.protected).UnaryServerInterceptor()
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.code {
+				t.Fatalf("code = %v, want %v (error %v)", status.Code(err), tt.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+type Store interface {
+	InsertAuditEvent(ctx context.Context, event *storage.AuditEvent) error
+}
+
+// Recorder persists security events. Failing to write an event is logged but
+// never fails the request that caused it.
+type Recorder struct {
+	store  Store
+	logger *slog.Logger
+}
+
+func New(store Store, logger *slog.Logger) *Recorder {
+	return &Recorder{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Record fills the gRPC method and peer IP from ctx and stores the event.
+func (r *Recorder) Record(ctx context.Context, event storage.AuditEvent) {
+	if event.Method == "" {
+		event.Method, _ = grpc.Method(ctx)
+	}
+	if event.PeerIP == "" {
+		event.PeerIP = peerIP(ctx)
+	}
+
+	// The event is kept even when the caller has gone away.
+	err := r.store.InsertAuditEvent(context.WithoutCancel(ctx), &event)
+	if err != nil {
+		r.logger.Error("failed to record audit event", "event", event.Event, "error", err)
+		return
+	}
+
+	metrics.AuditRecorded(event.Event, event.Outcome)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,20 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential returns the delay before retry number attempt (starting at 1):
+// base doubled for every previous attempt, capped at limit, with up to half of it randomised.
+func Exponential(attempt int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,35 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialBounds(t *testing.T) {
+	const (
+		base  = time.Second
+		limit = time.Minute
+	)
+
+	tests := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{attempt: 0, delay: base},
+		{attempt: 1, delay: base},
+		{attempt: 2, delay: 2 * base},
+		{attempt: 3, delay: 4 * base},
+		{attempt: 6, delay: 32 * base},
+		{attempt: 7, delay: limit},
+		{attempt: 1000, delay: limit},
+	}
+
+	for _, tt := range tests {
+		for range 100 {
+			got := Exponential(tt.attempt, base, limit)
+			if got < tt.delay/2 || got > tt.delay {
+				t.Fatalf("Exponential(%d) = %v, want within [%v, %v]", tt.attempt, got, tt.delay/2, tt.delay)
+			}
+		}
+	}
+}
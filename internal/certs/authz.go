@@ -0,0 +1,101 @@
+package certs
+
+import (
+	"context"
+	"slices"
+
+	"github.com/AndreyChufelin/movies-auth/internal/rpcmethod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Authorizer restricts methods to callers presenting a verified client
+// certificate with one of the allowed subject alternative names. Methods
+// without a rule are open to everyone.
+type Authorizer struct {
+	rules map[string][]string
+}
+
+// NewAuthorizer takes the allowed SANs keyed by method, given either as the
+// full gRPC method ("/user.UserService/VerifyToken"), its short name or "*",
+// as matched by rpcmethod.Matches. A call matching several rules is allowed
+// the SANs of all of them.
+func NewAuthorizer(rules map[string][]string) *Authorizer {
+	return &Authorizer{rules: rules}
+}
+
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (a *Authorizer) authorize(ctx context.Context, method string) error {
+	var (
+		allowed []string
+		ok      bool
+	)
+	for name, sans := range a.rules {
+		if rpcmethod.Matches(name, method) {
+			allowed = append(allowed, sans...)
+			ok = true
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	sans := clientSANs(ctx)
+	if sans == nil {
+		return status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	for _, san := range sans {
+		if slices.Contains(allowed, san) {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "client certificate not allowed to call this method")
+}
+
+// clientSANs returns the DNS and URI SANs of the verified client
+// certificate, or nil when the caller did not present one.
+func clientSANs(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	leaf := tlsInfo.State.VerifiedChains[0][0]
+	sans := make([]string, 0, len(leaf.DNSNames)+len(leaf.URIs))
+	sans = append(sans, leaf.DNSNames...)
+	for _, uri := range leaf.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
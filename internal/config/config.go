@@ -9,31 +9,219 @@ import (
 )
 
 type Config struct {
-	DB     DBConf
-	Mailer MailerConf
+	Server   ServerConf
+	Log      LogConf
+	DB       DBConf
+	Mailer   MailerConf
+	Webhooks WebhooksConf
+	Password PasswordConf
+	Metrics  MetricsConf
+	Tracing  TracingConf
+	Health   HealthConf
+	Debug    DebugConf
+	TLS      TLSConf
+	APIKeys  APIKeysConf `mapstructure:"api_keys"`
+	Tokens   TokensConf
 }
 
+type TokensConf struct {
+	ActivationTTL     time.Duration `mapstructure:"activation_ttl"`
+	AuthenticationTTL time.Duration `mapstructure:"authentication_ttl"`
+}
+
+type ServerConf struct {
+	// Address is the host:port the gRPC server listens on.
+	Address string
+	// ShutdownTimeout bounds the graceful stop of the servers and workers.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// MaxMessageSize limits received and sent messages, in bytes.
+	MaxMessageSize int `mapstructure:"max_message_size"`
+	Keepalive      KeepaliveConf
+}
+
+type KeepaliveConf struct {
+	// Time and Timeout control the pings sent to idle clients.
+	Time    time.Duration
+	Timeout time.Duration
+	// MaxConnectionIdle and MaxConnectionAge close connections gracefully;
+	// zero means no limit.
+	MaxConnectionIdle time.Duration `mapstructure:"max_connection_idle"`
+	MaxConnectionAge  time.Duration `mapstructure:"max_connection_age"`
+	// MinTime is the shortest ping interval a client may use before the
+	// connection is closed.
+	MinTime             time.Duration `mapstructure:"min_time"`
+	PermitWithoutStream bool          `mapstructure:"permit_without_stream"`
+}
+
+type APIKeysConf struct {
+	// Protected lists the methods, full or short names, that can only be
+	// called with an API key allowed to use them.
+	Protected []string
+}
+
+// TLSConf secures the gRPC listener. With ClientCAFile set, client
+// certificates are verified and Authorize limits methods to the listed SANs.
+type TLSConf struct {
+	Enabled        bool
+	CertFile       string        `mapstructure:"cert_file"`
+	KeyFile        string        `mapstructure:"key_file"`
+	ClientCAFile   string        `mapstructure:"client_ca_file"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+	Authorize      []MethodAuthorization
+}
+
+type MethodAuthorization struct {
+	// Method is the full gRPC method or its short name, e.g. VerifyToken.
+	Method string
+	SANs   []string `mapstructure:"sans"`
+}
+
+// DebugConf enables operator tooling that must not be reachable from
+// outside the host.
+type DebugConf struct {
+	// Reflection registers gRPC server reflection on the main listener.
+	Reflection bool
+	// Enabled starts a listener on Address serving pprof, expvar, build
+	// info and the effective configuration.
+	Enabled bool
+	Address string
+}
+
+type HealthConf struct {
+	Interval time.Duration
+	// Address serves /healthz and /readyz over HTTP; empty disables them.
+	Address string
+}
+
+type TracingConf struct {
+	Enabled bool
+	// Endpoint is the host:port of an OTLP/gRPC collector.
+	Endpoint    string
+	Insecure    bool
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type MetricsConf struct {
+	Enabled bool
+	Address string
+}
+
+type LogConf struct {
+	// Level is debug, info, warn or error.
+	Level string
+	// Format is json or text.
+	Format string
+}
+
+// DBConf locates the database either with DSN, a postgres:// URL or
+// keyword/value string also read from DATABASE_URL, or with the individual
+// settings below, which are ignored when DSN is set.
 type DBConf struct {
-	User         string
-	Password     string
-	Name         string
-	Host         string
-	Port         string
-	MaxOpenConns int           `mapstructure:"max_open_conns"`
+	DSN                string `mapstructure:"dsn" secret:"dsn"`
+	DSNFile            string `mapstructure:"dsn_file"`
+	User               string
+	Password           string `secret:"true"`
+	PasswordFile       string `mapstructure:"password_file"`
+	Name               string
+	Host               string
+	Port               string
+	SSLMode            string `mapstructure:"sslmode"`
+	SSLRootCert        string `mapstructure:"sslrootcert"`
+	TargetSessionAttrs string `mapstructure:"target_session_attrs"`
+	MaxOpenConns       int    `mapstructure:"max_open_conns"`
+	// MaxIdleConns is the number of connections the pool keeps open; idle
+	// connections above it are closed after MaxIdleTime.
 	MaxIdleConns int           `mapstructure:"max_idle_conns"`
 	MaxIdleTime  time.Duration `mapstructure:"max_idle_time"`
 }
 
 type MailerConf struct {
-	Host     string
-	Port     int
-	Username string
-	Password string
-	Sender   string
+	Transport    string
+	Host         string
+	Port         int
+	Username     string
+	Password     string `secret:"true"`
+	PasswordFile string `mapstructure:"password_file"`
+	TLS          string
+	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	Timeout      time.Duration
+	Dir          string
+	Sender       string
+	TemplatesDir string `mapstructure:"templates_dir"`
+	Branding     BrandingConf
+	Workers      int
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
 }
 
+type BrandingConf struct {
+	ProductName  string `mapstructure:"product_name"`
+	SupportEmail string `mapstructure:"support_email"`
+	URLs         map[string]string
+}
+
+type PasswordConf struct {
+	MinLength      int  `mapstructure:"min_length"`
+	MaxLength      int  `mapstructure:"max_length"`
+	RequireLower   bool `mapstructure:"require_lower"`
+	RequireUpper   bool `mapstructure:"require_upper"`
+	RequireDigit   bool `mapstructure:"require_digit"`
+	RequireSymbol  bool `mapstructure:"require_symbol"`
+	ForbidPersonal bool `mapstructure:"forbid_personal"`
+	// BreachedFile is a sorted list of SHA-1 hashes of breached passwords.
+	BreachedFile string `mapstructure:"breached_file"`
+	Hashing      HashingConf
+	Pepper       PepperConf
+}
+
+// PepperConf holds the HMAC keys applied to passwords before hashing, as
+// "id=base64key" pairs separated by commas or newlines. Keys is usually set
+// through PASSWORD_PEPPER_KEYS; KeysFile points at a mounted secret instead.
+type PepperConf struct {
+	Current  string
+	Keys     string `secret:"true"`
+	KeysFile string `mapstructure:"keys_file"`
+}
+
+type HashingConf struct {
+	// Algorithm is argon2id or bcrypt. Hashes from the other one are still
+	// verified and replaced on the next login.
+	Algorithm  string
+	Argon2id   Argon2idConf
+	BcryptCost int `mapstructure:"bcrypt_cost"`
+}
+
+type Argon2idConf struct {
+	// Memory is in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+type WebhooksConf struct {
+	Workers       int
+	PollInterval  time.Duration `mapstructure:"poll_interval"`
+	Timeout       time.Duration
+	MaxAttempts   int `mapstructure:"max_attempts"`
+	Subscriptions []WebhookSubscription
+}
+
+type WebhookSubscription struct {
+	Name       string
+	URL        string
+	Secret     string `secret:"true"`
+	SecretFile string `mapstructure:"secret_file"`
+	Events     []string
+}
+
+// LoadConfig reads the TOML file at path, applies environment overrides
+// and validates the result. Unknown keys are rejected to catch typos.
 func LoadConfig(path string) (Config, error) {
 	viper.SetConfigFile(path)
+	setDefaults()
 
 	err := viper.ReadInConfig()
 	if err != nil {
@@ -42,11 +230,50 @@ func LoadConfig(path string) (Config, error) {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	err = viper.BindEnv("db.dsn", "DB_DSN", "DATABASE_URL")
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to bind env: %w", err)
+	}
+
 	var config Config
-	err = viper.Unmarshal(&config)
+	err = viper.UnmarshalExact(&config)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	err = readSecretFiles(&config)
+	if err != nil {
+		return Config{}, err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
 	return config, nil
 }
+
+func setDefaults() {
+	viper.SetDefault("server.address", ":50051")
+	viper.SetDefault("server.shutdown_timeout", "10s")
+	viper.SetDefault("server.max_message_size", 4<<20)
+	viper.SetDefault("server.keepalive.time", "2h")
+	viper.SetDefault("server.keepalive.timeout", "20s")
+	viper.SetDefault("server.keepalive.min_time", "5m")
+	// Declared so that DB_PASSWORD_FILE and friends are picked up from the
+	// environment even when the file does not mention them.
+	viper.SetDefault("db.dsn_file", "")
+	viper.SetDefault("db.password_file", "")
+	viper.SetDefault("db.sslmode", "disable")
+	viper.SetDefault("mailer.username", "")
+	viper.SetDefault("mailer.password", "")
+	viper.SetDefault("mailer.password_file", "")
+	viper.SetDefault("password.pepper.keys_file", "")
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.format", "json")
+	viper.SetDefault("health.interval", "5s")
+	viper.SetDefault("tokens.activation_ttl", "72h")
+	viper.SetDefault("tokens.authentication_ttl", "24h")
+	viper.SetDefault("debug.address", "127.0.0.1:6060")
+}
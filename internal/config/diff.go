@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is a setting that differs between two configs, keyed as in the
+// TOML file, e.g. "log.level". Secrets are masked in Old and New.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+func Diff(prev, next Config) []Change {
+	var changes []Change
+	diffValue(
+		reflect.ValueOf(prev), reflect.ValueOf(next),
+		reflect.ValueOf(prev.Redacted()), reflect.ValueOf(next.Redacted()),
+		"", &changes,
+	)
+	return changes
+}
+
+func diffValue(prev, next, redactedPrev, redactedNext reflect.Value, key string, changes *[]Change) {
+	if prev.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(prev.Interface(), next.Interface()) {
+			*changes = append(*changes, Change{
+				Key: key,
+				Old: fmt.Sprint(redactedPrev.Interface()),
+				New: fmt.Sprint(redactedNext.Interface()),
+			})
+		}
+		return
+	}
+
+	for i := range prev.NumField() {
+		field := prev.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if key != "" {
+			name = key + "." + name
+		}
+		diffValue(prev.Field(i), next.Field(i), redactedPrev.Field(i), redactedNext.Field(i), name, changes)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	base := Config{
+		Log:    LogConf{Level: "info", Format: "json"},
+		Mailer: MailerConf{Username: "mailer", Password: "old-secret"},
+		Tokens: TokensConf{ActivationTTL: 72 * time.Hour},
+		Webhooks: WebhooksConf{
+			Subscriptions: []WebhookSubscription{{Name: "movies", Secret: "old-secret"}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []Change
+	}{
+		{
+			name:   "no change",
+			change: func(*Config) {},
+		},
+		{
+			name:   "plain setting",
+			change: func(c *Config) { c.Log.Level = "debug" },
+			want:   []Change{{Key: "log.level", Old: "info", New: "debug"}},
+		},
+		{
+			name:   "key from mapstructure tag",
+			change: func(c *Config) { c.Tokens.ActivationTTL = time.Hour },
+			want:   []Change{{Key: "tokens.activation_ttl", Old: "72h0m0s", New: "1h0m0s"}},
+		},
+		{
+			name:   "secret is masked",
+			change: func(c *Config) { c.Mailer.Password = "new-secret" },
+			want:   []Change{{Key: "mailer.password", Old: redacted, New: redacted}},
+		},
+		{
+			name:   "secret cleared",
+			change: func(c *Config) { c.Mailer.Password = "" },
+			want:   []Change{{Key: "mailer.password", Old: redacted, New: ""}},
+		},
+		{
+			name: "several settings",
+			change: func(c *Config) {
+				c.Log.Format = "text"
+				c.Mailer.Username = "other"
+			},
+			want: []Change{
+				{Key: "log.format", Old: "json", New: "text"},
+				{Key: "mailer.username", Old: "mailer", New: "other"},
+			},
+		},
+		{
+			name: "slice of structs is compared whole with secrets masked",
+			change: func(c *Config) {
+				c.Webhooks.Subscriptions = []WebhookSubscription{{Name: "movies", Secret: "new-secret"}}
+			},
+			want: []Change{{
+				Key: "webhooks.subscriptions",
+				Old: "[{movies  ******  []}]",
+				New: "[{movies  ******  []}]",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := base
+			next.Webhooks.Subscriptions = append([]WebhookSubscription(nil), base.Webhooks.Subscriptions...)
+			tt.change(&next)
+
+			got := Diff(base, next)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
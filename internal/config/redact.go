@@ -0,0 +1,79 @@
+package config
+
+import (
+	"log/slog"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+const redacted = "******"
+
+// Redacted returns a copy of c with every field tagged `secret:"true"` that
+// is set replaced by a placeholder, for logging and the debug server. Fields
+// tagged `secret:"dsn"` only have their password masked.
+func (c Config) Redacted() Config {
+	return redactValue(reflect.ValueOf(c)).Interface().(Config)
+}
+
+// redactedConfig has no LogValue method, so logging it does not recurse.
+type redactedConfig Config
+
+// LogValue makes a logged Config show up with its secrets masked.
+func (c Config) LogValue() slog.Value {
+	return slog.AnyValue(redactedConfig(c.Redacted()))
+}
+
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactDSN masks the password of a URL or keyword/value DSN the way pgx
+// does in its own errors.
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		if query := u.Query(); query.Has("password") {
+			query.Set("password", "xxxxx")
+			u.RawQuery = query.Encode()
+		}
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}xxxxx")
+}
+
+func redactValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if secret := field.Tag.Get("secret"); secret != "" && field.Type.Kind() == reflect.String {
+				value := v.Field(i).String()
+				switch {
+				case value == "":
+				case secret == "dsn":
+					out.Field(i).SetString(redactDSN(value))
+				default:
+					out.Field(i).SetString(redacted)
+				}
+				continue
+			}
+			out.Field(i).Set(redactValue(v.Field(i)))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(redactValue(v.Index(i)))
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const secretValue = "hunter2"
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		want string
+	}{
+		{
+			name: "url",
+			dsn:  "postgres://auth:hunter2@db:5432/auth?sslmode=verify-full",
+			want: "postgres://auth:xxxxx@db:5432/auth?sslmode=verify-full",
+		},
+		{
+			name: "url with password parameter",
+			dsn:  "postgresql://auth@db/auth?password=hunter2&sslmode=require",
+			want: "postgresql://auth@db/auth?password=xxxxx&sslmode=require",
+		},
+		{
+			name: "url without password",
+			dsn:  "postgres://auth@db/auth",
+			want: "postgres://auth@db/auth",
+		},
+		{
+			name: "keyword/value",
+			dsn:  "host=db user=auth password=hunter2 sslmode=require",
+			want: "host=db user=auth password=xxxxx sslmode=require",
+		},
+		{
+			name: "keyword/value with quoted password",
+			dsn:  `host=db password = 'hun ter\'2' dbname=auth`,
+			want: "host=db password = xxxxx dbname=auth",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactDSN(tt.dsn); got != tt.want {
+				t.Errorf("redactDSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fillSecrets sets every secret-tagged string field reachable from v,
+// growing empty slices by one element so their fields are covered too, and
+// returns the paths it set.
+func fillSecrets(v reflect.Value, path string) []string {
+	var filled []string
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			name := path + "." + field.Name
+			switch field.Tag.Get("secret") {
+			case "true":
+				v.Field(i).SetString(secretValue)
+				filled = append(filled, name)
+			case "dsn":
+				v.Field(i).SetString("postgres://auth:" + secretValue + "@db/auth")
+				filled = append(filled, name)
+			default:
+				filled = append(filled, fillSecrets(v.Field(i), name)...)
+			}
+		}
+	case reflect.Slice:
+		if v.Len() == 0 {
+			v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		}
+		for i := range v.Len() {
+			filled = append(filled, fillSecrets(v.Index(i), path+"[]")...)
+		}
+	default:
+	}
+	return filled
+}
+
+// findSecret returns the paths of string fields still holding the secret.
+func findSecret(v reflect.Value, path string) []string {
+	var found []string
+	switch v.Kind() {
+	case reflect.String:
+		if strings.Contains(v.String(), secretValue) {
+			found = append(found, path)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			found = append(found, findSecret(v.Field(i), path+"."+v.Type().Field(i).Name)...)
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			found = append(found, findSecret(v.Index(i), path+"[]")...)
+		}
+	default:
+	}
+	return found
+}
+
+func TestRedactedMasksEverySecretField(t *testing.T) {
+	var c Config
+	filled := fillSecrets(reflect.ValueOf(&c).Elem(), "Config")
+	if len(filled) == 0 {
+		t.Fatal("no secret-tagged fields found")
+	}
+
+	if leaked := findSecret(reflect.ValueOf(c.Redacted()), "Config"); len(leaked) > 0 {
+		t.Errorf("Redacted() leaks %v", leaked)
+	}
+	if leaked := findSecret(reflect.ValueOf(c), "Config"); len(leaked) != len(filled) {
+		t.Errorf("Redacted() modified the original config: %v still set, want %v", leaked, filled)
+	}
+}
+
+func TestLogValueMasksSecrets(t *testing.T) {
+	var c Config
+	fillSecrets(reflect.ValueOf(&c).Elem(), "Config")
+	c.Log.Level = "debug"
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("config loaded", "config", c)
+
+	if strings.Contains(buf.String(), secretValue) {
+		t.Errorf("logged config leaks a secret: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"Level":"debug"`) {
+		t.Errorf("logged config lost non-secret settings: %s", buf.String())
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// readSecretFiles fills every secret field from the file named by its
+// <Field>File sibling, e.g. DB.Password from DB.PasswordFile, so secrets can
+// come from Docker or Kubernetes secret mounts. A file wins over a value set
+// directly.
+func readSecretFiles(c *Config) error {
+	return readSecretFilesValue(reflect.ValueOf(c).Elem(), "")
+}
+
+func readSecretFilesValue(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := path + field.Name
+
+			if field.Tag.Get("secret") == "" {
+				err := readSecretFilesValue(v.Field(i), name+".")
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			file := v.FieldByName(field.Name + "File")
+			if !file.IsValid() || file.String() == "" {
+				continue
+			}
+			data, err := os.ReadFile(file.String())
+			if err != nil {
+				return fmt.Errorf("failed to read %s from file: %w", name, err)
+			}
+			v.Field(i).SetString(strings.TrimRight(string(data), "\r\n"))
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			err := readSecretFilesValue(v.Index(i), fmt.Sprintf("%s[%d].", strings.TrimSuffix(path, "."), i))
+			if err != nil {
+				return err
+			}
+		}
+	default:
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecret(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadSecretFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(c *Config, file string)
+		got   func(c Config) string
+		want  string
+	}{
+		{
+			name:  "db password",
+			setup: func(c *Config, file string) { c.DB.PasswordFile = file },
+			got:   func(c Config) string { return c.DB.Password },
+			want:  "hunter2",
+		},
+		{
+			name:  "file wins over a direct value",
+			setup: func(c *Config, file string) { c.DB.Password = "inline"; c.DB.PasswordFile = file },
+			got:   func(c Config) string { return c.DB.Password },
+			want:  "hunter2",
+		},
+		{
+			name:  "dsn",
+			setup: func(c *Config, file string) { c.DB.DSNFile = file },
+			got:   func(c Config) string { return c.DB.DSN },
+			want:  "hunter2",
+		},
+		{
+			name:  "nested pepper keys",
+			setup: func(c *Config, file string) { c.Password.Pepper.KeysFile = file },
+			got:   func(c Config) string { return c.Password.Pepper.Keys },
+			want:  "hunter2",
+		},
+		{
+			name: "webhook secret inside a slice",
+			setup: func(c *Config, file string) {
+				c.Webhooks.Subscriptions = []WebhookSubscription{{Name: "a"}, {Name: "b", SecretFile: file}}
+			},
+			got: func(c Config) string {
+				return c.Webhooks.Subscriptions[0].Secret + "|" + c.Webhooks.Subscriptions[1].Secret
+			},
+			want: "|hunter2",
+		},
+		{
+			name:  "no file keeps the direct value",
+			setup: func(c *Config, _ string) { c.Mailer.Password = "inline" },
+			got:   func(c Config) string { return c.Mailer.Password },
+			want:  "inline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			tt.setup(&c, writeSecret(t, "hunter2\n"))
+
+			err := readSecretFiles(&c)
+			if err != nil {
+				t.Fatalf("readSecretFiles() error = %v", err)
+			}
+			if got := tt.got(c); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSecretFilesMissingFile(t *testing.T) {
+	var c Config
+	c.Mailer.PasswordFile = filepath.Join(t.TempDir(), "missing")
+
+	err := readSecretFiles(&c)
+	if err == nil || !strings.Contains(err.Error(), "Mailer.Password") {
+		t.Fatalf("readSecretFiles() error = %v, want one naming Mailer.Password", err)
+	}
+}
+
+func TestLoadConfigReadsSecretFilesFromEnv(t *testing.T) {
+	t.Setenv("DB_PASSWORD_FILE", writeSecret(t, "db-secret\n"))
+	t.Setenv("MAILER_PASSWORD_FILE", writeSecret(t, "smtp-secret"))
+
+	c, err := LoadConfig("../../configs/config-auth.toml")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if c.DB.Password != "db-secret" {
+		t.Errorf("DB.Password = %q", c.DB.Password)
+	}
+	if c.Mailer.Password != "smtp-secret" {
+		t.Errorf("Mailer.Password = %q", c.Mailer.Password)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Validate reports every problem in the configuration at once, one per
+// line, so a broken deployment can be fixed in a single pass.
+func (c Config) Validate() error {
+	var v validation
+
+	v.address("server.address", c.Server.Address)
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	v.check(c.Server.MaxMessageSize > 0, "server.max_message_size must be positive")
+	v.check(c.Server.Keepalive.Time >= 0 && c.Server.Keepalive.Timeout >= 0 &&
+		c.Server.Keepalive.MaxConnectionIdle >= 0 && c.Server.Keepalive.MaxConnectionAge >= 0 &&
+		c.Server.Keepalive.MinTime >= 0,
+		"server.keepalive durations must not be negative")
+
+	v.oneOf("log.level", strings.ToLower(c.Log.Level), "debug", "info", "warn", "error")
+	v.oneOf("log.format", strings.ToLower(c.Log.Format), "json", "text")
+
+	if c.DB.DSN == "" {
+		v.required("db.host", c.DB.Host)
+		v.required("db.name", c.DB.Name)
+		v.required("db.user", c.DB.User)
+		v.port("db.port", c.DB.Port)
+		v.oneOf("db.sslmode", c.DB.SSLMode,
+			"disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+		v.oneOf("db.target_session_attrs", c.DB.TargetSessionAttrs,
+			"", "any", "read-write", "read-only", "primary", "standby", "prefer-standby")
+		v.check(c.DB.SSLRootCert == "" || c.DB.SSLMode == "verify-ca" || c.DB.SSLMode == "verify-full" ||
+			c.DB.SSLMode == "require", "db.sslrootcert requires db.sslmode require, verify-ca or verify-full")
+	}
+	v.check(c.DB.MaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	v.check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	v.check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns must not exceed db.max_open_conns")
+	v.check(c.DB.MaxIdleTime >= 0, "db.max_idle_time must not be negative")
+
+	v.check(c.Tokens.ActivationTTL > 0, "tokens.activation_ttl must be positive")
+	v.check(c.Tokens.AuthenticationTTL > 0, "tokens.authentication_ttl must be positive")
+
+	c.validateMailer(&v)
+	c.validateWebhooks(&v)
+	c.validatePassword(&v)
+
+	if c.Metrics.Enabled {
+		v.address("metrics.address", c.Metrics.Address)
+	}
+	if c.Tracing.Enabled {
+		v.required("tracing.endpoint", c.Tracing.Endpoint)
+		v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+			"tracing.sample_ratio must be between 0 and 1")
+	}
+	v.check(c.Health.Interval > 0, "health.interval must be positive")
+	if c.Health.Address != "" {
+		v.address("health.address", c.Health.Address)
+	}
+	if c.Debug.Enabled {
+		v.address("debug.address", c.Debug.Address)
+	}
+
+	if c.TLS.Enabled {
+		v.required("tls.cert_file", c.TLS.CertFile)
+		v.required("tls.key_file", c.TLS.KeyFile)
+		v.check(len(c.TLS.Authorize) == 0 || c.TLS.ClientCAFile != "",
+			"tls.authorize requires tls.client_ca_file")
+	} else {
+		v.check(len(c.TLS.Authorize) == 0, "tls.authorize requires tls.enabled")
+	}
+	for i, rule := range c.TLS.Authorize {
+		v.required(fmt.Sprintf("tls.authorize[%d].method", i), rule.Method)
+		v.check(len(rule.SANs) > 0, "tls.authorize[%d].sans must not be empty", i)
+	}
+
+	return v.err()
+}
+
+func (c Config) validateMailer(v *validation) {
+	switch c.Mailer.Transport {
+	case "", "smtp":
+		v.required("mailer.host", c.Mailer.Host)
+		v.check(c.Mailer.Port > 0 && c.Mailer.Port <= 65535, "mailer.port must be between 1 and 65535")
+		v.oneOf("mailer.tls", c.Mailer.TLS, "", "starttls", "implicit", "none")
+		v.check(c.Mailer.Timeout >= 0, "mailer.timeout must not be negative")
+	case "file":
+		v.required("mailer.dir", c.Mailer.Dir)
+	case "memory":
+	default:
+		v.fail("mailer.transport must be smtp, file or memory, got %q", c.Mailer.Transport)
+	}
+
+	_, err := mail.ParseAddress(c.Mailer.Sender)
+	v.check(err == nil, "mailer.sender must be an email address, got %q", c.Mailer.Sender)
+	v.check(c.Mailer.Workers >= 0, "mailer.workers must not be negative")
+	v.check(c.Mailer.MaxAttempts >= 0, "mailer.max_attempts must not be negative")
+	v.check(c.Mailer.PollInterval >= 0, "mailer.poll_interval must not be negative")
+}
+
+func (c Config) validateWebhooks(v *validation) {
+	v.check(c.Webhooks.Workers >= 0, "webhooks.workers must not be negative")
+	v.check(c.Webhooks.MaxAttempts >= 0, "webhooks.max_attempts must not be negative")
+	v.check(c.Webhooks.Timeout >= 0, "webhooks.timeout must not be negative")
+
+	names := make(map[string]bool, len(c.Webhooks.Subscriptions))
+	for i, sub := range c.Webhooks.Subscriptions {
+		field := fmt.Sprintf("webhooks.subscriptions[%d]", i)
+		v.required(field+".name", sub.Name)
+		v.check(!names[sub.Name], "%s.name %q is used more than once", field, sub.Name)
+		names[sub.Name] = true
+
+		u, err := url.Parse(sub.URL)
+		v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"%s.url must be an absolute http(s) URL", field)
+		v.required(field+".secret", sub.Secret)
+		v.check(len(sub.Events) > 0, "%s.events must not be empty", field)
+	}
+}
+
+func (c Config) validatePassword(v *validation) {
+	v.check(c.Password.MinLength >= 0, "password.min_length must not be negative")
+	v.check(c.Password.MaxLength == 0 || c.Password.MaxLength >= c.Password.MinLength,
+		"password.max_length must not be less than password.min_length")
+	v.oneOf("password.hashing.algorithm", c.Password.Hashing.Algorithm, "", "argon2id", "bcrypt")
+	v.check(c.Password.Pepper.Current == "" || c.Password.Pepper.Keys != "",
+		"password.pepper.current requires password.pepper.keys or password.pepper.keys_file")
+}
+
+type validation struct {
+	errs []error
+}
+
+func (v *validation) fail(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validation) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.fail(format, args...)
+	}
+}
+
+func (v *validation) required(field, value string) {
+	v.check(value != "", "%s is required", field)
+}
+
+func (v *validation) oneOf(field, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), "%s must be one of %q, got %q", field, allowed, value)
+}
+
+func (v *validation) port(field, value string) {
+	port, err := strconv.Atoi(value)
+	v.check(err == nil && port > 0 && port <= 65535, "%s must be a port number, got %q", field, value)
+}
+
+func (v *validation) address(field, value string) {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		v.fail("%s must be host:port, got %q", field, value)
+		return
+	}
+	v.port(field, port)
+}
+
+func (v *validation) err() error {
+	return errors.Join(v.errs...)
+}
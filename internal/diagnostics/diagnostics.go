@@ -0,0 +1,94 @@
+// Package diagnostics serves operator tooling: pprof profiles, expvar
+// counters, build information and the effective configuration. Besides the
+// runtime values, /debug/vars carries the service metrics (logins, tokens,
+// audit events, outbox backlog, pool usage) under "metrics", so they can be
+// read without a Prometheus scrape. The handler has no authentication and
+// must only be bound to a private address.
+package diagnostics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+)
+
+var started = time.Now()
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() any {
+		return runtime.NumGoroutine()
+	}))
+	expvar.Publish("uptime_seconds", expvar.Func(func() any {
+		return int64(time.Since(started).Seconds())
+	}))
+	expvar.Publish("metrics", expvar.Func(func() any {
+		values, err := metrics.Snapshot()
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		return values
+	}))
+}
+
+type buildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+	Deps      map[string]string `json:"deps"`
+}
+
+// Handler returns the debug mux. config is called on every request to
+// /debug/config and must return a value with secrets already masked.
+func Handler(config func() any) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/buildinfo", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, readBuildInfo())
+	})
+	mux.HandleFunc("/debug/config", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, config())
+	})
+	return mux
+}
+
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		GoVersion: runtime.Version(),
+		Settings:  map[string]string{},
+		Deps:      map[string]string{},
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path = bi.Main.Path
+	info.Version = bi.Main.Version
+	for _, setting := range bi.Settings {
+		info.Settings[setting.Key] = setting.Value
+	}
+	for _, dep := range bi.Deps {
+		info.Deps[dep.Path] = dep.Version
+	}
+
+	return info
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
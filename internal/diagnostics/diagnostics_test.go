@@ -0,0 +1,40 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+)
+
+func TestVarsPublishServiceMetrics(t *testing.T) {
+	metrics.TokenIssued("activation")
+	metrics.AuditRecorded("user.created", "success")
+
+	rec := httptest.NewRecorder()
+	Handler(func() any { return nil }).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	var vars struct {
+		Metrics map[string]float64 `json:"metrics"`
+	}
+	err := json.Unmarshal(rec.Body.Bytes(), &vars)
+	if err != nil {
+		t.Fatalf("decoding /debug/vars: %v", err)
+	}
+
+	for _, key := range []string{
+		`auth_tokens_issued_total{scope="activation"}`,
+		`auth_audit_events_total{event="user.created",outcome="success"}`,
+	} {
+		if vars.Metrics[key] < 1 {
+			t.Errorf("metrics[%s] = %v, want at least 1 (got %v)", key, vars.Metrics[key], vars.Metrics)
+		}
+	}
+	for key := range vars.Metrics {
+		if key == "go_goroutines" {
+			t.Errorf("runtime metric %s published under metrics", key)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const checkTimeout = 3 * time.Second
+
+// Check probes one dependency. Only critical checks decide whether the
+// service is serving; the others are reported for information.
+type Check struct {
+	Name     string
+	Critical bool
+	Probe    func(ctx context.Context) error
+}
+
+type result struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Checker runs the checks periodically and reports the outcome through the
+// standard grpc.health.v1 service and, optionally, over HTTP. It starts out
+// NOT_SERVING until the first round of checks passes.
+type Checker struct {
+	*health.Server
+
+	logger   *slog.Logger
+	checks   []Check
+	services []string
+	interval time.Duration
+
+	mu           sync.RWMutex
+	results      map[string]result
+	ready        bool
+	shuttingDown bool
+}
+
+// New creates a Checker that reports on the overall server ("") and on
+// every service in services.
+func New(logger *slog.Logger, interval time.Duration, services []string, checks ...Check) *Checker {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	c := &Checker{
+		Server:   health.NewServer(),
+		logger:   logger.With("component", "health"),
+		checks:   checks,
+		services: append([]string{""}, services...),
+		interval: interval,
+		results:  make(map[string]result),
+	}
+	c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return c
+}
+
+// Run checks the dependencies every interval until ctx is done.
+func (c *Checker) Run(ctx context.Context) {
+	for {
+		c.runChecks(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.interval):
+		}
+	}
+}
+
+func (c *Checker) runChecks(ctx context.Context) {
+	results := make(map[string]result, len(c.checks))
+	ready := true
+
+	for _, check := range c.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := check.Probe(checkCtx)
+		cancel()
+
+		r := result{Status: "ok", Critical: check.Critical, CheckedAt: time.Now()}
+		if err != nil {
+			r.Status = "failing"
+			r.Error = err.Error()
+			if check.Critical {
+				ready = false
+			}
+		}
+		results[check.Name] = r
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, r := range results {
+		if previous, ok := c.results[name]; ok && previous.Status == r.Status {
+			continue
+		}
+		if r.Error != "" {
+			c.logger.Warn("dependency check failing", "check", name, "error", r.Error)
+		} else {
+			c.logger.Info("dependency check passing", "check", name)
+		}
+	}
+	c.results = results
+
+	if c.shuttingDown || ready == c.ready {
+		return
+	}
+	c.ready = ready
+	if ready {
+		c.setStatus(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// Shutdown reports NOT_SERVING from now on, so that load balancers stop
+// sending new requests while in-flight ones finish.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	c.shuttingDown = true
+	c.ready = false
+	c.mu.Unlock()
+
+	c.Server.Shutdown()
+}
+
+func (c *Checker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range c.services {
+		c.SetServingStatus(service, status)
+	}
+}
+
+// Handler serves /healthz, which only tells that the process is up, and
+// /readyz, which details every check and fails until the critical ones pass.
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		c.mu.RLock()
+		ready, shuttingDown := c.ready, c.shuttingDown
+		checks := make(map[string]result, len(c.results))
+		for name, r := range c.results {
+			checks[name] = r
+		}
+		c.mu.RUnlock()
+
+		status, code := "ready", http.StatusOK
+		switch {
+		case shuttingDown:
+			status, code = "shutting down", http.StatusServiceUnavailable
+		case !ready:
+			status, code = "not ready", http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, map[string]any{"status": status, "checks": checks})
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
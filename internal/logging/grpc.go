@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader is read from incoming metadata and echoed back in the
+// response headers.
+const RequestIDHeader = "x-request-id"
+
+const maxRequestIDLength = 128
+
+func UnaryServerInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, logg := requestLogger(ctx, logger, info.FullMethod)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCompleted(ctx, logg, start, err)
+
+		return resp, err
+	}
+}
+
+func StreamServerInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, logg := requestLogger(ss.Context(), logger, info.FullMethod)
+
+		start := time.Now()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		logCompleted(ctx, logg, start, err)
+
+		return err
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestLogger takes the caller's request id, or assigns one, and returns
+// a context carrying a logger tagged with it.
+func requestLogger(ctx context.Context, logger *slog.Logger, method string) (context.Context, *slog.Logger) {
+	id := incomingRequestID(ctx)
+	if id == "" {
+		id = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+	logg := logger.With("request_id", id, "method", method)
+	return WithLogger(ctx, logg), logg
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(RequestIDHeader)
+	if len(values) == 0 || len(values[0]) > maxRequestIDLength {
+		return ""
+	}
+	return values[0]
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func logCompleted(ctx context.Context, logg *slog.Logger, start time.Time, err error) {
+	code := status.Code(err)
+
+	attrs := []any{
+		"code", code.String(),
+		"duration", time.Since(start),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+	if err != nil {
+		attrs = append(attrs, "error", status.Convert(err).Message())
+	}
+
+	logg.Log(ctx, levelForCode(code), "request completed", attrs...)
+}
+
+// levelForCode logs server faults as errors and caller mistakes as warnings.
+func levelForCode(code codes.Code) slog.Level {
+	switch {
+	case code == codes.OK:
+		return slog.LevelInfo
+	case code == codes.Unknown, code == codes.Internal, code == codes.DataLoss,
+		code == codes.Unimplemented, code == codes.DeadlineExceeded, code == codes.Unavailable:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
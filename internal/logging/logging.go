@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// New builds the service logger. The returned LevelVar can change the level
+// of a running logger.
+func New(w io.Writer, level, format string) (*slog.Logger, *slog.LevelVar, error) {
+	levelVar := new(slog.LevelVar)
+	err := SetLevel(levelVar, level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       levelVar,
+		ReplaceAttr: redact,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return slog.New(handler), levelVar, nil
+}
+
+// SetLevel parses level ("debug", "info", "warn" or "error") into levelVar.
+func SetLevel(levelVar *slog.LevelVar, level string) error {
+	if level == "" {
+		level = "info"
+	}
+
+	var l slog.Level
+	err := l.UnmarshalText([]byte(level))
+	if err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	levelVar.Set(l)
+
+	return nil
+}
+
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext returns the request logger, or slog.Default outside of a
+// request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
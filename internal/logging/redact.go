@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// secretKeys are attribute keys whose values are never logged, matched
+// case-insensitively as a substring so that "new_password" and
+// "activation_token" are covered too.
+var secretKeys = []string{"password", "token", "secret", "authorization", "pepper", "api_key"}
+
+// redact hides secrets by attribute key and masks every email address found
+// in string values, keeping the domain for debugging.
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(redactEmails(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			attr.Value = slog.StringValue(redactEmails(err.Error()))
+		}
+	}
+
+	return attr
+}
+
+func redactEmails(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		local, domain, _ := strings.Cut(email, "@")
+		return local[:1] + "***@" + domain
+	})
+}
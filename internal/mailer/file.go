@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DirTransport writes every message as an .eml file into a directory, for
+// local development without an SMTP server.
+type DirTransport struct {
+	dir string
+}
+
+func NewDirTransport(dir string) (*DirTransport, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	return &DirTransport{dir: dir}, nil
+}
+
+func (t *DirTransport) Send(msg *Message) error {
+	suffix := make([]byte, 4)
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	f, err := os.OpenFile(filepath.Join(t.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create email file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = msg.toGomail().WriteTo(f)
+	if err != nil {
+		return fmt.Errorf("failed to write email file: %w", err)
+	}
+
+	return f.Close()
+}
@@ -2,62 +2,422 @@ package mailer
 
 import (
 	"bytes"
+	"context"
 	"embed"
-	"html/template"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"text/template/parse"
 
-	mail "gopkg.in/gomail.v2"
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 //go:embed "templates"
 var templateFS embed.FS
 
+const layoutDir = "layout/"
+
+var tracer = otel.Tracer("github.com/AndreyChufelin/movies-auth/internal/mailer")
+
+var requiredBlocks = []string{"subject", "plainBody", "htmlBody"}
+
+// Transport delivers a rendered message.
+type Transport interface {
+	Send(msg *Message) error
+}
+
+// Suppressions reports addresses that hard-bounced or complained and must
+// not be mailed again.
+type Suppressions interface {
+	IsEmailSuppressed(ctx context.Context, email string) (bool, error)
+}
+
+// Branding is the data shared by every email. URLs are text/template strings
+// executed with the data passed to Send, e.g.
+// "https://movies.example.com/activate?token={{.activationToken | query}}".
+type Branding struct {
+	ProductName  string
+	SupportEmail string
+	URLs         map[string]string
+}
+
+// TemplateData is what email templates are executed with.
+type TemplateData struct {
+	Product      string
+	SupportEmail string
+	Recipient    string
+	Locale       string
+	URLs         map[string]string
+	Data         map[string]any
+}
+
+// emailTemplate keeps the subject and plain body in text/template, so that
+// they are not HTML-escaped, and the HTML body in html/template.
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
 type Mailer struct {
-	dialer *mail.Dialer
-	sender string
+	transport    Transport
+	suppressions Suppressions
+	sender       string
+	branding     Branding
+	urls         map[string]*texttemplate.Template
+
+	mu           sync.RWMutex
+	templatesDir string
+	templates    map[string]emailTemplate
 }
 
-func New(host string, port int, username, password, sender string) Mailer {
-	dialer := mail.NewDialer(host, port, username, password)
-	return Mailer{
-		dialer: dialer,
-		sender: sender,
+// New parses the embedded templates, overridden file by file by templatesDir
+// when it is set, and fails if any template lacks one of the subject,
+// plainBody and htmlBody blocks or uses a URL that branding does not define.
+// suppressions may be nil.
+func New(
+	transport Transport,
+	suppressions Suppressions,
+	sender, templatesDir string,
+	branding Branding,
+) (*Mailer, error) {
+	m := &Mailer{
+		transport:    transport,
+		suppressions: suppressions,
+		sender:       sender,
+		templatesDir: templatesDir,
+		branding:     branding,
+		urls:         make(map[string]*texttemplate.Template, len(branding.URLs)),
+	}
+
+	funcs := texttemplate.FuncMap{"query": url.QueryEscape}
+	for name, raw := range branding.URLs {
+		tmpl, err := texttemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s url template: %w", name, err)
+		}
+		m.urls[name] = tmpl
+	}
+
+	err := m.Reload()
+	if err != nil {
+		return nil, err
 	}
+
+	return m, nil
+}
+
+// Reload re-reads template overrides from disk. On error the previously
+// loaded templates stay in use.
+func (m *Mailer) Reload() error {
+	m.mu.RLock()
+	dir := m.templatesDir
+	m.mu.RUnlock()
+
+	return m.SetTemplatesDir(dir)
 }
 
-func (m Mailer) Send(recipient, templateFile string, data interface{}) error {
-	tmpl, err := template.New("email").ParseFS(templateFS, "templates/"+templateFile)
+// SetTemplatesDir loads the overrides from dir, which may be empty to use
+// only the embedded templates. On error the current directory and templates
+// stay in use.
+func (m *Mailer) SetTemplatesDir(dir string) error {
+	sources, err := loadSources(dir)
 	if err != nil {
 		return err
 	}
 
-	subject := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(subject, "subject", data)
+	templates, err := compileTemplates(sources)
 	if err != nil {
 		return err
 	}
 
-	plainBody := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(plainBody, "plainBody", data)
+	for name, tmpl := range templates {
+		for _, key := range usedURLs(tmpl.text) {
+			if m.urls[key] == nil {
+				return fmt.Errorf("template %s uses .URLs.%s, which mailer.branding.urls does not set", name, key)
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.templatesDir = dir
+	m.templates = templates
+	m.mu.Unlock()
+
+	return nil
+}
+
+// loadSources returns template sources keyed by their path relative to the
+// templates directory, with files from dir replacing embedded ones.
+func loadSources(dir string) (map[string]string, error) {
+	sources := make(map[string]string)
+
+	embedded, err := fs.Sub(templateFS, "templates")
 	if err != nil {
-		return err
+		return nil, err
+	}
+	err = readSources(embedded, sources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded templates: %w", err)
 	}
 
-	htmlBody := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(htmlBody, "htmlBody", data)
+	if dir != "" {
+		err = readSources(os.DirFS(dir), sources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates from %s: %w", dir, err)
+		}
+	}
+
+	return sources, nil
+}
+
+func readSources(fsys fs.FS, sources map[string]string) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(name) != ".tmpl" {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sources[name] = string(content)
+		return nil
+	})
+}
+
+func compileTemplates(sources map[string]string) (map[string]emailTemplate, error) {
+	var layouts []string
+	for name := range sources {
+		if strings.HasPrefix(name, layoutDir) {
+			layouts = append(layouts, name)
+		}
+	}
+	slices.Sort(layouts)
+
+	templates := make(map[string]emailTemplate)
+	for name := range sources {
+		if strings.Contains(name, "/") {
+			continue
+		}
+
+		text := texttemplate.New(name).Option("missingkey=error")
+		html := htmltemplate.New(name).Option("missingkey=error")
+		for _, file := range slices.Concat(layouts, []string{name}) {
+			_, err := text.Parse(sources[file])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse template %s: %w", file, err)
+			}
+			_, err = html.Parse(sources[file])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse template %s: %w", file, err)
+			}
+		}
+
+		for _, block := range requiredBlocks {
+			if text.Lookup(block) == nil {
+				return nil, fmt.Errorf("template %s: missing %q block", name, block)
+			}
+		}
+
+		templates[name] = emailTemplate{text: text, html: html}
+	}
+
+	return templates, nil
+}
+
+// usedURLs returns the keys of the .URLs fields referenced by tmpl and the
+// templates associated with it.
+func usedURLs(tmpl *texttemplate.Template) []string {
+	var keys []string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(&n.BranchNode)
+		case *parse.RangeNode:
+			walk(&n.BranchNode)
+		case *parse.WithNode:
+			walk(&n.BranchNode)
+		case *parse.BranchNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				for _, arg := range cmd.Args {
+					walk(arg)
+				}
+			}
+		case *parse.FieldNode:
+			if len(n.Ident) >= 2 && n.Ident[0] == "URLs" && !slices.Contains(keys, n.Ident[1]) {
+				keys = append(keys, n.Ident[1])
+			}
+		}
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root)
+		}
+	}
+	return keys
+}
+
+// lookup resolves templateFile for locale, trying e.g. user_welcome.pt-BR.tmpl,
+// then user_welcome.pt.tmpl, and falling back to user_welcome.tmpl.
+func (m *Mailer) lookup(templateFile, locale string) (emailTemplate, error) {
+	name := strings.TrimSuffix(templateFile, ".tmpl")
+
+	var candidates []string
+	if locale != "" {
+		candidates = append(candidates, name+"."+locale+".tmpl")
+		if lang, _, ok := strings.Cut(locale, "-"); ok {
+			candidates = append(candidates, name+"."+lang+".tmpl")
+		}
+	}
+	candidates = append(candidates, path.Base(templateFile))
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, candidate := range candidates {
+		if tmpl, ok := m.templates[candidate]; ok {
+			return tmpl, nil
+		}
+	}
+
+	return emailTemplate{}, fmt.Errorf("template %s not found", templateFile)
+}
+
+func (m *Mailer) templateData(recipient, locale string, data map[string]any) (TemplateData, error) {
+	td := TemplateData{
+		Product:      m.branding.ProductName,
+		SupportEmail: m.branding.SupportEmail,
+		Recipient:    recipient,
+		Locale:       locale,
+		URLs:         make(map[string]string, len(m.urls)),
+		Data:         data,
+	}
+
+	var errs []error
+	for name, tmpl := range m.urls {
+		buf := new(bytes.Buffer)
+		err := tmpl.Execute(buf, data)
+		if err != nil {
+			// Not every email carries the data every URL needs; only the
+			// URLs a template actually uses have to resolve.
+			errs = append(errs, err)
+			continue
+		}
+		td.URLs[name] = buf.String()
+	}
+
+	return td, errors.Join(errs...)
+}
+
+func (m *Mailer) Send(ctx context.Context, recipient, locale, templateFile string, data map[string]any) (err error) {
+	ctx, span := tracer.Start(ctx, "Mailer.Send", trace.WithAttributes(
+		attribute.String("email.template", templateFile),
+		attribute.String("email.locale", locale),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if m.suppressions != nil {
+		suppressed, err := m.suppressions.IsEmailSuppressed(ctx, recipient)
+		if err != nil {
+			metrics.EmailAttempted(metrics.EmailSendFail)
+			return fmt.Errorf("failed to check suppression list: %w", err)
+		}
+		if suppressed {
+			metrics.EmailAttempted(metrics.EmailSuppressed)
+			return storage.ErrEmailSuppressed
+		}
+	}
+
+	msg, err := m.render(recipient, locale, templateFile, data)
 	if err != nil {
+		metrics.EmailAttempted(metrics.EmailRenderFail)
 		return err
 	}
 
-	msg := mail.NewMessage()
-	msg.SetHeader("To", recipient)
-	msg.SetHeader("From", m.sender)
-	msg.SetHeader("Subject", subject.String())
-	msg.SetBody("text/plain", plainBody.String())
-	msg.AddAlternative("text/html", htmlBody.String())
-
-	err = m.dialer.DialAndSend(msg)
+	err = m.transport.Send(msg)
 	if err != nil {
+		metrics.EmailAttempted(metrics.EmailSendFail)
 		return err
 	}
+
+	metrics.EmailAttempted(metrics.EmailSent)
 	return nil
 }
+
+func (m *Mailer) render(recipient, locale, templateFile string, data map[string]any) (*Message, error) {
+	tmpl, err := m.lookup(templateFile, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	td, urlErr := m.templateData(recipient, locale, data)
+
+	subject := new(bytes.Buffer)
+	err = tmpl.text.ExecuteTemplate(subject, "subject", td)
+	if err != nil {
+		return nil, errors.Join(err, urlErr)
+	}
+
+	plainBody := new(bytes.Buffer)
+	err = tmpl.text.ExecuteTemplate(plainBody, "plainBody", td)
+	if err != nil {
+		return nil, errors.Join(err, urlErr)
+	}
+
+	htmlBody := new(bytes.Buffer)
+	err = tmpl.html.ExecuteTemplate(htmlBody, "htmlBody", td)
+	if err != nil {
+		return nil, errors.Join(err, urlErr)
+	}
+
+	return &Message{
+		From:      m.sender,
+		To:        recipient,
+		Subject:   subject.String(),
+		PlainBody: plainBody.String(),
+		HTMLBody:  htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,228 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var recorder = tracetest.NewSpanRecorder()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+}
+
+type suppressionList map[string]bool
+
+func (s suppressionList) IsEmailSuppressed(_ context.Context, email string) (bool, error) {
+	return s[email], nil
+}
+
+func TestMailerSend(t *testing.T) {
+	branding := Branding{
+		ProductName:  "Movies",
+		SupportEmail: "support@example.com",
+		URLs: map[string]string{
+			"activation": "https://movies.example.com/activate?token={{.activationToken | query}}",
+		},
+	}
+	data := map[string]any{"userID": 42, "activationToken": "abc", "activationExpiry": "2026-10-22 10:00 UTC"}
+
+	tests := []struct {
+		name      string
+		recipient string
+		locale    string
+		err       error
+		subject   string
+		body      []string
+	}{
+		{
+			name:      "default locale",
+			recipient: "alice@example.com",
+			subject:   "Welcome to Movies!",
+			body: []string{
+				"user ID number is 42",
+				"https://movies.example.com/activate?token=abc",
+				"it will expire on 2026-10-22 10:00 UTC.",
+			},
+		},
+		{
+			name:      "regional locale falls back to language",
+			recipient: "alice@example.com",
+			locale:    "ru-RU",
+			subject:   "Добро пожаловать в Movies!",
+			body:      []string{"идентификатор пользователя — 42", "действует до 2026-10-22 10:00 UTC."},
+		},
+		{
+			name:      "unknown locale falls back to default",
+			recipient: "alice@example.com",
+			locale:    "de",
+			subject:   "Welcome to Movies!",
+		},
+		{
+			name:      "suppressed recipient",
+			recipient: "bounced@example.com",
+			err:       storage.ErrEmailSuppressed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := NewMemoryTransport()
+			suppressions := suppressionList{"bounced@example.com": true}
+			m, err := New(transport, suppressions, "movies@example.com", "", branding)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			err = m.Send(context.Background(), tt.recipient, tt.locale, "user_welcome.tmpl", data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.err)
+			}
+
+			msg, ok := transport.Last()
+			if tt.err != nil {
+				if ok {
+					t.Fatalf("message was sent despite error: %+v", msg)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("no message was sent")
+			}
+
+			if msg.To != tt.recipient || msg.From != "movies@example.com" {
+				t.Errorf("message is from %q to %q", msg.From, msg.To)
+			}
+			if msg.Subject != tt.subject {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.subject)
+			}
+			for _, want := range tt.body {
+				if !strings.Contains(msg.PlainBody, want) {
+					t.Errorf("PlainBody does not contain %q:\n%s", want, msg.PlainBody)
+				}
+				if !strings.Contains(msg.HTMLBody, want) {
+					t.Errorf("HTMLBody does not contain %q:\n%s", want, msg.HTMLBody)
+				}
+			}
+		})
+	}
+}
+
+// Emails queued before the expiry was added to their data still render.
+func TestMailerSendWithoutActivationExpiry(t *testing.T) {
+	transport := NewMemoryTransport()
+	m, err := New(transport, nil, "movies@example.com", "", Branding{
+		ProductName: "Movies",
+		URLs:        map[string]string{"activation": "https://movies.example.com/activate"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = m.Send(context.Background(), "alice@example.com", "", "user_welcome.tmpl", map[string]any{"userID": 42})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	msg, _ := transport.Last()
+	if !strings.Contains(msg.PlainBody, "this is a one-time use link.") {
+		t.Errorf("PlainBody = %q", msg.PlainBody)
+	}
+}
+
+func TestMailerSendSpan(t *testing.T) {
+	branding := Branding{URLs: map[string]string{"activation": "https://movies.example.com/activate"}}
+	m, err := New(NewMemoryTransport(), suppressionList{"bounced@example.com": true}, "movies@example.com", "", branding)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		recipient string
+		status    codes.Code
+	}{
+		{name: "sent", recipient: "alice@example.com", status: codes.Unset},
+		{name: "suppressed", recipient: "bounced@example.com", status: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := len(recorder.Ended())
+			err := m.Send(context.Background(), tt.recipient, "ru", "user_welcome.tmpl", map[string]any{"userID": 1})
+			if (err != nil) != (tt.status == codes.Error) {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			spans := recorder.Ended()[n:]
+			if len(spans) != 1 {
+				t.Fatalf("recorded %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != "Mailer.Send" {
+				t.Errorf("name = %q", span.Name())
+			}
+			want := []attribute.KeyValue{
+				attribute.String("email.template", "user_welcome.tmpl"),
+				attribute.String("email.locale", "ru"),
+			}
+			for _, kv := range want {
+				found := false
+				for _, got := range span.Attributes() {
+					found = found || got == kv
+				}
+				if !found {
+					t.Errorf("missing attribute %s=%s in %v", kv.Key, kv.Value.Emit(), span.Attributes())
+				}
+			}
+			if span.Status().Code != tt.status {
+				t.Errorf("status = %v, want %v", span.Status().Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestNewRequiresUsedURLs(t *testing.T) {
+	_, err := New(NewMemoryTransport(), nil, "movies@example.com", "", Branding{ProductName: "Movies"})
+	if err == nil || !strings.Contains(err.Error(), ".URLs.activation") {
+		t.Fatalf("New() error = %v, want one naming .URLs.activation", err)
+	}
+}
+
+func TestSetTemplatesDirRequiresUsedURLs(t *testing.T) {
+	m, err := New(NewMemoryTransport(), nil, "movies@example.com", "", Branding{
+		ProductName: "Movies",
+		URLs:        map[string]string{"activation": "https://movies.example.com/activate"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	override := `{{define "subject"}}Reset{{end}}
+{{define "plainBody"}}{{if .Data}}{{.URLs.reset}}{{end}}{{end}}
+{{define "htmlBody"}}<p>Reset</p>{{end}}`
+	err = os.WriteFile(filepath.Join(dir, "password_reset.tmpl"), []byte(override), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.SetTemplatesDir(dir)
+	if err == nil || !strings.Contains(err.Error(), ".URLs.reset") {
+		t.Fatalf("SetTemplatesDir() error = %v, want one naming .URLs.reset", err)
+	}
+	if _, err := m.lookup("password_reset.tmpl", ""); err == nil {
+		t.Error("rejected templates were put in use")
+	}
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import "sync"
+
+// MemoryTransport keeps sent messages in memory so tests can inspect the
+// rendered subject and bodies.
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(msg *Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = append(t.messages, *msg)
+	return nil
+}
+
+func (t *MemoryTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	messages := make([]Message, len(t.messages))
+	copy(messages, t.messages)
+	return messages
+}
+
+// Last returns the most recent message and false if nothing was sent.
+func (t *MemoryTransport) Last() (Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.messages) == 0 {
+		return Message{}, false
+	}
+	return t.messages[len(t.messages)-1], true
+}
+
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = nil
+}
@@ -0,0 +1,21 @@
+package mailer
+
+import mail "gopkg.in/gomail.v2"
+
+type Message struct {
+	From      string
+	To        string
+	Subject   string
+	PlainBody string
+	HTMLBody  string
+}
+
+func (m *Message) toGomail() *mail.Message {
+	msg := mail.NewMessage()
+	msg.SetHeader("To", m.To)
+	msg.SetHeader("From", m.From)
+	msg.SetHeader("Subject", m.Subject)
+	msg.SetBody("text/plain", m.PlainBody)
+	msg.AddAlternative("text/html", m.HTMLBody)
+	return msg
+}
@@ -0,0 +1,315 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mail "gopkg.in/gomail.v2"
+)
+
+const (
+	TLSStartTLS = "starttls"
+	TLSImplicit = "implicit"
+	// TLSNone is for local catch-all servers such as MailHog: the connection
+	// stays in plain text and credentials are never sent.
+	TLSNone = "none"
+)
+
+// ErrStartTLSUnsupported is returned in STARTTLS mode when the server does
+// not offer the extension, so credentials are never sent in the clear.
+var ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+
+// SMTPTransport keeps one SMTP connection open between sends and closes it
+// after idleTimeout without traffic. Connecting and sending each message must
+// finish within timeout, so a stalled server cannot hold the connection, and
+// every worker waiting for it, forever.
+type SMTPTransport struct {
+	dialer      *mail.Dialer
+	plaintext   bool
+	idleTimeout time.Duration
+	timeout     time.Duration
+
+	mu        sync.Mutex
+	conn      mail.SendCloser
+	idleTimer *time.Timer
+}
+
+func NewSMTPTransport(
+	host string,
+	port int,
+	username, password, tlsMode string,
+	idleTimeout, timeout time.Duration,
+) (*SMTPTransport, error) {
+	dialer := mail.NewDialer(host, port, username, password)
+	dialer.TLSConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	var plaintext bool
+	switch tlsMode {
+	case "", TLSStartTLS:
+	case TLSImplicit:
+		dialer.SSL = true
+	case TLSNone:
+		plaintext = true
+	default:
+		return nil, fmt.Errorf("unknown smtp tls mode %q", tlsMode)
+	}
+
+	if idleTimeout <= 0 {
+		idleTimeout = 30 * time.Second
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &SMTPTransport{
+		dialer:      dialer,
+		plaintext:   plaintext,
+		idleTimeout: idleTimeout,
+		timeout:     timeout,
+	}, nil
+}
+
+// SetCredentials changes the login used for new connections. The open
+// connection is closed so the next email authenticates with them.
+func (t *SMTPTransport) SetCredentials(username, password string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.dialer.Username = username
+	t.dialer.Password = password
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
+	}
+}
+
+// Ping checks that the SMTP server accepts connections.
+func (t *SMTPTransport) Ping(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(t.dialer.Host, strconv.Itoa(t.dialer.Port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (t *SMTPTransport) Send(msg *Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m := msg.toGomail()
+
+	reused := t.conn != nil
+	err := t.send(m)
+	if err != nil && reused {
+		// The server may have dropped the idle connection; retry once on a
+		// fresh one.
+		err = t.send(m)
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.idleTimer == nil {
+		t.idleTimer = time.AfterFunc(t.idleTimeout, t.closeIdle)
+	} else {
+		t.idleTimer.Reset(t.idleTimeout)
+	}
+
+	return nil
+}
+
+func (t *SMTPTransport) send(m *mail.Message) error {
+	if t.conn == nil {
+		conn, err := t.dial()
+		if err != nil {
+			return fmt.Errorf("failed to dial smtp server: %w", err)
+		}
+		t.conn = conn
+	}
+
+	err := mail.Send(t.conn, m)
+	if err != nil {
+		t.conn.Close()
+		t.conn = nil
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// dial connects and authenticates like mail.Dialer.Dial, except that in
+// STARTTLS mode it fails unless the server upgrades the connection.
+func (t *SMTPTransport) dial() (mail.SendCloser, error) {
+	d := t.dialer
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(d.Host, strconv.Itoa(d.Port)), t.timeout)
+	if err != nil {
+		return nil, err
+	}
+	// Covers the greeting, STARTTLS and AUTH; Send moves it for each message.
+	err = conn.SetDeadline(time.Now().Add(t.timeout))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if d.SSL {
+		conn = tls.Client(conn, d.TLSConfig)
+	}
+
+	c, err := smtp.NewClient(conn, d.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if t.plaintext {
+		return smtpSender{Client: c, conn: conn, timeout: t.timeout}, nil
+	}
+
+	if !d.SSL {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			c.Close()
+			return nil, ErrStartTLSUnsupported
+		}
+		err = c.StartTLS(d.TLSConfig)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	if d.Username != "" {
+		if ok, mechanisms := c.Extension("AUTH"); ok {
+			err = c.Auth(smtpAuth(mechanisms, d.Host, d.Username, d.Password))
+			if err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return smtpSender{Client: c, conn: conn, timeout: t.timeout}, nil
+}
+
+// smtpAuth picks the mechanism the same way gomail does.
+func smtpAuth(mechanisms, host, username, password string) smtp.Auth {
+	switch {
+	case strings.Contains(mechanisms, "CRAM-MD5"):
+		return smtp.CRAMMD5Auth(username, password)
+	case strings.Contains(mechanisms, "LOGIN") && !strings.Contains(mechanisms, "PLAIN"):
+		return &loginAuth{username: username, password: password}
+	default:
+		return smtp.PlainAuth("", username, password, host)
+	}
+}
+
+type loginAuth struct {
+	username, password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSuffix(string(fromServer), ":")) {
+	case "username":
+		return []byte(a.username), nil
+	case "password":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
+
+// smtpSender adapts smtp.Client to mail.SendCloser. The deadline of conn,
+// which the client is built on, also bounds the exchanges after STARTTLS.
+type smtpSender struct {
+	*smtp.Client
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (c smtpSender) Send(from string, to []string, msg io.WriterTo) error {
+	err := c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if err != nil {
+		return err
+	}
+
+	err = c.Mail(from)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		err = c.Rcpt(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = msg.WriteTo(w)
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+func (c smtpSender) Close() error {
+	err := c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if err != nil {
+		c.Client.Close()
+		return err
+	}
+	return c.Quit()
+}
+
+func (t *SMTPTransport) closeIdle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
+	}
+}
+
+func (t *SMTPTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.idleTimer != nil {
+		t.idleTimer.Stop()
+	}
+	if t.conn == nil {
+		return nil
+	}
+
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
@@ -0,0 +1,213 @@
+package mailer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer speaks just enough SMTP for one client and records the
+// commands it received. It never offers STARTTLS.
+type fakeSMTPServer struct {
+	listener net.Listener
+	// stallOn makes the server stop answering once it receives this command.
+	stallOn string
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			conn.Write([]byte(line + "\r\n"))
+		}
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, _, _ := strings.Cut(line, " ")
+		verb = strings.ToUpper(verb)
+
+		s.mu.Lock()
+		s.commands = append(s.commands, verb)
+		s.mu.Unlock()
+
+		if verb == s.stallOn {
+			_, _ = io.Copy(io.Discard, r)
+			return
+		}
+
+		switch verb {
+		case "EHLO":
+			reply("250-localhost", "250 AUTH PLAIN LOGIN")
+		case "DATA":
+			reply("354 go ahead")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+			}
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+func TestSMTPTransportTLSModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		tlsMode  string
+		err      error
+		commands []string
+	}{
+		{
+			name:     "starttls refuses a server without the extension",
+			tlsMode:  TLSStartTLS,
+			err:      ErrStartTLSUnsupported,
+			commands: []string{"EHLO"},
+		},
+		{
+			name:     "default mode is starttls",
+			tlsMode:  "",
+			err:      ErrStartTLSUnsupported,
+			commands: []string{"EHLO"},
+		},
+		{
+			name:     "none delivers without sending credentials",
+			tlsMode:  TLSNone,
+			commands: []string{"EHLO", "MAIL", "RCPT", "DATA"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t)
+			host, port, err := net.SplitHostPort(server.listener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			portNum, err := strconv.Atoi(port)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			transport, err := NewSMTPTransport(host, portNum, "user", "secret", tt.tlsMode, time.Minute, time.Minute)
+			if err != nil {
+				t.Fatalf("NewSMTPTransport() error = %v", err)
+			}
+			defer transport.Close()
+
+			err = transport.Send(&Message{
+				From:      "movies@example.com",
+				To:        "alice@example.com",
+				Subject:   "Hello",
+				PlainBody: "plain",
+				HTMLBody:  "<p>html</p>",
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.err)
+			}
+
+			got := server.Commands()
+			for _, command := range got {
+				if command == "AUTH" {
+					t.Fatalf("credentials were sent without TLS: %v", got)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.commands, " ") {
+				t.Errorf("commands = %v, want %v", got, tt.commands)
+			}
+		})
+	}
+}
+
+func TestNewSMTPTransportRejectsUnknownTLSMode(t *testing.T) {
+	_, err := NewSMTPTransport("localhost", 25, "", "", "opportunistic", time.Minute, time.Minute)
+	if err == nil {
+		t.Fatal("NewSMTPTransport() accepted an unknown tls mode")
+	}
+}
+
+func TestSMTPTransportTimesOutOnStalledServer(t *testing.T) {
+	for _, stallOn := range []string{"EHLO", "MAIL"} {
+		t.Run(stallOn, func(t *testing.T) {
+			server := newFakeSMTPServer(t)
+			server.stallOn = stallOn
+			addr := server.listener.Addr().(*net.TCPAddr)
+
+			transport, err := NewSMTPTransport(addr.IP.String(), addr.Port, "", "", TLSNone, time.Minute, 100*time.Millisecond)
+			if err != nil {
+				t.Fatalf("NewSMTPTransport() error = %v", err)
+			}
+			defer transport.Close()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- transport.Send(&Message{From: "movies@example.com", To: "alice@example.com", Subject: "Hello"})
+			}()
+
+			select {
+			case err := <-done:
+				// gomail flattens the error, so only its text is left.
+				if err == nil || !strings.Contains(err.Error(), "i/o timeout") {
+					t.Fatalf("Send() error = %v, want a timeout", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Send() blocked on a stalled server")
+			}
+		})
+	}
+}
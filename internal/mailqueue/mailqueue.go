@@ -0,0 +1,113 @@
+// Package mailqueue sends the emails queued in the outbox by account
+// changes, retrying failed sends with exponential backoff.
+package mailqueue
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/config"
+	"github.com/AndreyChufelin/movies-auth/internal/queue"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	batchSize = 10
+	baseDelay = 10 * time.Second
+	maxDelay  = 30 * time.Minute
+	lease     = 5 * time.Minute
+)
+
+var tracer = otel.Tracer("github.com/AndreyChufelin/movies-auth/internal/mailqueue")
+
+// Store is the email outbox. MarkEmailSent may drop the template data, but
+// MarkEmailFailed must keep it, as a parked email can be retried later.
+type Store interface {
+	ClaimEmails(ctx context.Context, limit int, lease time.Duration) ([]*storage.Email, error)
+	MarkEmailSent(ctx context.Context, id int64) error
+	MarkEmailFailed(ctx context.Context, id int64, lastError string, nextAttempt time.Time, failed bool) error
+}
+
+type Mailer interface {
+	Send(ctx context.Context, recipient, locale, templateFile string, data map[string]any) error
+}
+
+type Worker struct {
+	store  Store
+	mailer Mailer
+	logger *slog.Logger
+	queue  queue.Queue[*storage.Email]
+	retry  queue.Retry
+}
+
+func New(store Store, mailer Mailer, logger *slog.Logger, conf config.MailerConf) *Worker {
+	w := &Worker{
+		store:  store,
+		mailer: mailer,
+		logger: logger.With("component", "email delivery"),
+		retry: queue.Retry{
+			BaseDelay:   baseDelay,
+			MaxDelay:    maxDelay,
+			MaxAttempts: conf.MaxAttempts,
+		},
+	}
+	if w.retry.MaxAttempts <= 0 {
+		w.retry.MaxAttempts = 10
+	}
+
+	w.queue = queue.Queue[*storage.Email]{
+		Logger:       w.logger,
+		Workers:      conf.Workers,
+		BatchSize:    batchSize,
+		PollInterval: conf.PollInterval,
+		Lease:        lease,
+		Claim:        store.ClaimEmails,
+		Handle:       w.deliver,
+	}
+	if w.queue.PollInterval <= 0 {
+		w.queue.PollInterval = time.Second
+	}
+
+	return w
+}
+
+// Run sends queued emails until ctx is done. The email being sent is
+// finished before it returns.
+func (w *Worker) Run(ctx context.Context) {
+	w.queue.Run(ctx)
+}
+
+func (w *Worker) deliver(ctx context.Context, email *storage.Email) {
+	logg := w.logger.With("email_id", email.ID)
+
+	ctx, span := tracer.Start(ctx, "deliverEmail", trace.WithAttributes(
+		attribute.Int64("email.id", email.ID),
+		attribute.Int("email.attempt", email.Attempts+1),
+	))
+	defer span.End()
+
+	err := w.mailer.Send(ctx, email.Recipient, email.Locale, email.Template, email.Data)
+	if err != nil {
+		next, failed := w.retry.Next(email.Attempts + 1)
+		// Retrying a suppressed address can't succeed; the email waits
+		// for an operator to clear the suppression and retry it.
+		failed = failed || errors.Is(err, storage.ErrEmailSuppressed)
+		logg.Warn("failed to send email", "attempt", email.Attempts+1, "failed", failed, "error", err)
+
+		err = w.store.MarkEmailFailed(ctx, email.ID, err.Error(), next, failed)
+		if err != nil {
+			logg.Error("failed to mark email failed", "error", err)
+		}
+		return
+	}
+
+	err = w.store.MarkEmailSent(ctx, email.ID)
+	if err != nil {
+		logg.Error("failed to mark email sent", "error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package mailqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/config"
+	"github.com/AndreyChufelin/movies-auth/internal/mailer"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+)
+
+// outbox follows the Store contract in memory, storing data as JSON the way
+// the jsonb column does.
+type outbox struct {
+	emails map[int64]*storage.Email
+}
+
+func (o *outbox) ClaimEmails(context.Context, int, time.Duration) ([]*storage.Email, error) {
+	return nil, nil
+}
+
+func (o *outbox) MarkEmailSent(_ context.Context, id int64) error {
+	email := o.emails[id]
+	email.Status = storage.EmailSent
+	email.Attempts++
+	email.Data = map[string]any{}
+	return nil
+}
+
+func (o *outbox) MarkEmailFailed(_ context.Context, id int64, lastError string, next time.Time, failed bool) error {
+	email := o.emails[id]
+	email.Status = storage.EmailPending
+	if failed {
+		email.Status = storage.EmailFailed
+	}
+	email.Attempts++
+	email.LastError = lastError
+	email.NextAttemptAt = next
+	return nil
+}
+
+func (o *outbox) retry(id int64) *storage.Email {
+	email := *o.emails[id]
+	email.Status = storage.EmailPending
+	email.Attempts = 0
+	return &email
+}
+
+// flakyTransport fails until it is told to deliver.
+type flakyTransport struct {
+	*mailer.MemoryTransport
+	down bool
+}
+
+func (t *flakyTransport) Send(msg *mailer.Message) error {
+	if t.down {
+		return errors.New("connection refused")
+	}
+	return t.MemoryTransport.Send(msg)
+}
+
+func TestRetryParkedWelcomeEmail(t *testing.T) {
+	transport := &flakyTransport{MemoryTransport: mailer.NewMemoryTransport(), down: true}
+	m, err := mailer.New(transport, nil, "movies@example.com", "", mailer.Branding{
+		ProductName: "Movies",
+		URLs: map[string]string{
+			"activation": "https://movies.example.com/activate?token={{.activationToken | query}}",
+		},
+	})
+	if err != nil {
+		t.Fatalf("mailer.New() error = %v", err)
+	}
+
+	user := &storage.User{ID: 42, Email: "alice@example.com"}
+	welcome := storage.NewEmail(user, "user_welcome.tmpl", map[string]any{"activationToken": "abc"})
+	welcome.PrepareData()
+	raw, err := json.Marshal(welcome.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	welcome.ID = 1
+	welcome.Data = nil
+	if err := json.Unmarshal(raw, &welcome.Data); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &outbox{emails: map[int64]*storage.Email{welcome.ID: welcome}}
+	w := New(store, m, slog.New(slog.NewTextHandler(io.Discard, nil)), config.MailerConf{MaxAttempts: 2})
+
+	for range 2 {
+		w.deliver(context.Background(), store.emails[welcome.ID])
+	}
+	if welcome.Status != storage.EmailFailed {
+		t.Fatalf("status = %q after two failures, want %q", welcome.Status, storage.EmailFailed)
+	}
+
+	transport.down = false
+	w.deliver(context.Background(), store.retry(welcome.ID))
+
+	msg, ok := transport.Last()
+	if !ok {
+		t.Fatalf("retried email was not sent: %s", store.emails[welcome.ID].LastError)
+	}
+	for _, want := range []string{"user ID number is 42", "https://movies.example.com/activate?token=abc"} {
+		if !strings.Contains(msg.PlainBody, want) {
+			t.Errorf("PlainBody does not contain %q:\n%s", want, msg.PlainBody)
+		}
+	}
+}
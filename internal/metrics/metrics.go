@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "auth"
+
+// Login failure reasons.
+const (
+	LoginUserNotFound    = "user_not_found"
+	LoginInvalidPassword = "invalid_password"
+	LoginSuspended       = "suspended"
+)
+
+// Reasons tokens are deleted before expiry.
+const (
+	TokenConsumed = "consumed"
+	TokenRevoked  = "revoked"
+)
+
+// Email send outcomes.
+const (
+	EmailSent       = "sent"
+	EmailSuppressed = "suppressed"
+	EmailRenderFail = "render_failed"
+	EmailSendFail   = "send_failed"
+)
+
+// Registry holds every collector of the service, along with the Go runtime
+// and process ones.
+var Registry = prometheus.NewRegistry()
+
+var (
+	rpcHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Time spent handling gRPC requests, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by outcome and failure reason.",
+	}, []string{"outcome", "reason"})
+
+	tokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_issued_total",
+		Help:      "Tokens issued, by scope.",
+	}, []string{"scope"})
+
+	tokensRevoked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_revoked_total",
+		Help:      "Tokens deleted before expiry, by scope and reason.",
+	}, []string{"scope", "reason"})
+
+	auditEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audit_events_total",
+		Help:      "Audit events recorded, by event and outcome.",
+	}, []string{"event", "outcome"})
+
+	emails = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_total",
+		Help:      "Email send attempts, by outcome.",
+	}, []string{"outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcHandled,
+		rpcDuration,
+		logins,
+		tokensIssued,
+		tokensRevoked,
+		auditEvents,
+		emails,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Snapshot returns the current value of every service metric, keyed by name
+// and labels in the exposition format, e.g. `auth_logins_total{outcome="success",reason=""}`.
+// Histograms contribute their _count and _sum. Go runtime and process metrics
+// are left out.
+func Snapshot() (map[string]float64, error) {
+	families, err := Registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), namespace+"_") {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			var labels strings.Builder
+			for i, label := range m.GetLabel() {
+				if i > 0 {
+					labels.WriteByte(',')
+				}
+				labels.WriteString(label.GetName() + "=" + strconv.Quote(label.GetValue()))
+			}
+			key := func(suffix string) string {
+				if labels.Len() == 0 {
+					return family.GetName() + suffix
+				}
+				return family.GetName() + suffix + "{" + labels.String() + "}"
+			}
+
+			switch {
+			case m.GetCounter() != nil:
+				values[key("")] = m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				values[key("")] = m.GetGauge().GetValue()
+			case m.GetHistogram() != nil:
+				values[key("_count")] = float64(m.GetHistogram().GetSampleCount())
+				values[key("_sum")] = m.GetHistogram().GetSampleSum()
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func LoginSucceeded() {
+	logins.WithLabelValues("success", "").Inc()
+}
+
+func LoginFailed(reason string) {
+	logins.WithLabelValues("failure", reason).Inc()
+}
+
+func TokenIssued(scope string) {
+	tokensIssued.WithLabelValues(scope).Inc()
+}
+
+func TokensRevoked(scope, reason string, count int) {
+	tokensRevoked.WithLabelValues(scope, reason).Add(float64(count))
+}
+
+func AuditRecorded(event, outcome string) {
+	auditEvents.WithLabelValues(event, outcome).Inc()
+}
+
+func EmailAttempted(outcome string) {
+	emails.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OutboxCollector exposes the number of pending emails and webhooks, counted
+// in the database on every scrape.
+type OutboxCollector struct {
+	backlog func(ctx context.Context) (map[string]int64, error)
+
+	pending *prometheus.Desc
+	up      *prometheus.Desc
+}
+
+func NewOutboxCollector(backlog func(ctx context.Context) (map[string]int64, error)) *OutboxCollector {
+	return &OutboxCollector{
+		backlog: backlog,
+		pending: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "outbox", "pending"),
+			"Messages waiting to be sent, by outbox.",
+			[]string{"outbox"}, nil,
+		),
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "outbox", "backlog_up"),
+			"Whether the outbox backlog could be counted on the last scrape.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *OutboxCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.pending
+	ch <- c.up
+}
+
+func (c *OutboxCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	backlog, err := c.backlog(ctx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+	for outbox, count := range backlog {
+		ch <- prometheus.MustNewConstMetric(c.pending, prometheus.GaugeValue, float64(count), outbox)
+	}
+}
@@ -0,0 +1,129 @@
+package password
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// Argon2Params are the argon2id cost parameters. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2id encodes hashes in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+type Argon2id struct {
+	Params Argon2Params
+}
+
+func (a Argon2id) Hash(plaintext string) ([]byte, error) {
+	salt := make([]byte, a.Params.SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	p := a.Params
+	key := argon2.IDKey([]byte(plaintext), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return []byte(fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)), nil
+}
+
+func (a Argon2id) Verify(plaintext string, hash []byte) (bool, error) {
+	decoded, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	if decoded.version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2 version %d", decoded.version)
+	}
+
+	p := decoded.params
+	key := argon2.IDKey([]byte(plaintext), decoded.salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, decoded.key) == 1, nil
+}
+
+func (a Argon2id) Owns(hash []byte) bool {
+	return bytes.HasPrefix(hash, []byte("$argon2id$"))
+}
+
+func (a Argon2id) Outdated(hash []byte) bool {
+	decoded, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return decoded.version != argon2.Version || decoded.params != a.Params
+}
+
+type argon2idHash struct {
+	version int
+	params  Argon2Params
+	salt    []byte
+	key     []byte
+}
+
+func decodeArgon2id(hash []byte) (*argon2idHash, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, errInvalidArgon2Hash
+	}
+
+	decoded := &argon2idHash{}
+	_, err := fmt.Sscanf(parts[2], "v=%d", &decoded.version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidArgon2Hash, err)
+	}
+
+	p := &decoded.params
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidArgon2Hash, err)
+	}
+	if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 {
+		return nil, fmt.Errorf("%w: zero cost parameter", errInvalidArgon2Hash)
+	}
+
+	decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidArgon2Hash, err)
+	}
+	decoded.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidArgon2Hash, err)
+	}
+	if len(decoded.salt) == 0 || len(decoded.key) == 0 {
+		return nil, fmt.Errorf("%w: empty salt or key", errInvalidArgon2Hash)
+	}
+	p.SaltLength = uint32(len(decoded.salt))
+	p.KeyLength = uint32(len(decoded.key))
+
+	return decoded, nil
+}
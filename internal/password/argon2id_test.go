@@ -0,0 +1,76 @@
+package password
+
+import (
+	"errors"
+	"testing"
+)
+
+var testArgon2Params = Argon2Params{
+	Memory:      8,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  8,
+	KeyLength:   16,
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	a := Argon2id{Params: testArgon2Params}
+
+	hash, err := a.Hash("pa55word")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	ok, err := a.Verify("pa55word", hash)
+	if err != nil || !ok {
+		t.Fatalf("Verify(correct) = %v, %v", ok, err)
+	}
+	ok, err = a.Verify("wrong", hash)
+	if err != nil || ok {
+		t.Fatalf("Verify(wrong) = %v, %v", ok, err)
+	}
+	if a.Outdated(hash) {
+		t.Error("Outdated() = true for a hash with the current params")
+	}
+}
+
+func TestDecodeArgon2idRejectsMalformedHashes(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"too few fields", "$argon2id$v=19$m=8,t=1,p=1$c2FsdHNhbHQ"},
+		{"too many fields", "$argon2id$v=19$m=8,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5$x"},
+		{"other algorithm", "$argon2i$v=19$m=8,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"bad version", "$argon2id$v=x$m=8,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"bad params", "$argon2id$v=19$m=8;t=1;p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"zero memory", "$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"zero iterations", "$argon2id$v=19$m=8,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"zero parallelism", "$argon2id$v=19$m=8,t=1,p=0$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"parallelism overflow", "$argon2id$v=19$m=8,t=1,p=256$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"negative memory", "$argon2id$v=19$m=-8,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"empty salt", "$argon2id$v=19$m=8,t=1,p=1$$a2V5a2V5a2V5a2V5"},
+		{"empty key", "$argon2id$v=19$m=8,t=1,p=1$c2FsdHNhbHQ$"},
+		{"bad salt encoding", "$argon2id$v=19$m=8,t=1,p=1$c2Fs!HNhbHQ$a2V5a2V5a2V5a2V5"},
+		{"bad key encoding", "$argon2id$v=19$m=8,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5=="},
+	}
+
+	a := Argon2id{Params: testArgon2Params}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeArgon2id([]byte(tt.hash))
+			if !errors.Is(err, errInvalidArgon2Hash) {
+				t.Fatalf("decodeArgon2id() error = %v, want %v", err, errInvalidArgon2Hash)
+			}
+
+			ok, err := a.Verify("pa55word", []byte(tt.hash))
+			if err == nil || ok {
+				t.Errorf("Verify() = %v, %v, want an error", ok, err)
+			}
+			if !a.Outdated([]byte(tt.hash)) {
+				t.Error("Outdated() = false for a malformed hash")
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1" //nolint:gosec // the corpus is keyed by SHA-1
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// prefixLength is the k-anonymity range size used by Have I Been Pwned.
+const prefixLength = 5
+
+type span struct {
+	start, end int64
+}
+
+// Corpus is a breached-password list in the Have I Been Pwned downloader
+// format: one upper-case SHA-1 hash per line, optionally followed by
+// ":count", sorted by hash. Only an index of hash prefixes is kept in memory;
+// a lookup reads the single range its prefix falls in.
+type Corpus struct {
+	file   *os.File
+	ranges map[string]span
+}
+
+func LoadCorpus(path string) (*Corpus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password corpus: %w", err)
+	}
+
+	ranges, err := indexCorpus(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to index breached password corpus %s: %w", path, err)
+	}
+
+	return &Corpus{file: file, ranges: ranges}, nil
+}
+
+func indexCorpus(r io.Reader) (map[string]span, error) {
+	ranges := make(map[string]span)
+	reader := bufio.NewReaderSize(r, 1<<20)
+
+	var offset int64
+	var current string
+	for {
+		line, err := reader.ReadSlice('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(line)) >= prefixLength {
+			prefix := string(bytes.ToUpper(line[:prefixLength]))
+			if prefix != current {
+				if prev, ok := ranges[prefix]; ok && prev.end != offset {
+					return nil, fmt.Errorf("hash prefix %s is not contiguous, the file must be sorted", prefix)
+				}
+				current = prefix
+				ranges[prefix] = span{start: offset}
+			}
+			s := ranges[prefix]
+			s.end = offset + int64(len(line))
+			ranges[prefix] = s
+		}
+		offset += int64(len(line))
+
+		if errors.Is(err, io.EOF) {
+			return ranges, nil
+		}
+	}
+}
+
+// Contains reports whether the password's SHA-1 is in the corpus.
+func (c *Corpus) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // the corpus is keyed by SHA-1
+	hash := bytes.ToUpper([]byte(hex.EncodeToString(sum[:])))
+
+	s, ok := c.ranges[string(hash[:prefixLength])]
+	if !ok {
+		return false, nil
+	}
+
+	block := make([]byte, s.end-s.start)
+	_, err := c.file.ReadAt(block, s.start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	for _, line := range bytes.Split(block, []byte("\n")) {
+		line, _, _ = bytes.Cut(bytes.TrimSpace(line), []byte(":"))
+		if bytes.EqualFold(line, hash) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *Corpus) Close() error {
+	return c.file.Close()
+}
@@ -0,0 +1,106 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Algorithm is a single password hashing scheme.
+type Algorithm interface {
+	Hash(plaintext string) ([]byte, error)
+	Verify(plaintext string, hash []byte) (bool, error)
+	// Owns reports whether hash was produced by this algorithm.
+	Owns(hash []byte) bool
+	// Outdated reports whether an owned hash was produced with parameters
+	// other than the configured ones.
+	Outdated(hash []byte) bool
+}
+
+// Hasher hashes new passwords with the current algorithm and still verifies
+// hashes produced by the legacy ones. With a pepper, passwords are run through
+// HMAC with the current key first and the key id is recorded in the hash.
+type Hasher struct {
+	current Algorithm
+	legacy  []Algorithm
+	pepper  *Pepper
+}
+
+func NewHasher(current Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{current: current, legacy: legacy}
+}
+
+// WithPepper returns a copy of h that peppers new hashes with pepper.Current.
+func (h *Hasher) WithPepper(pepper *Pepper) *Hasher {
+	peppered := *h
+	peppered.pepper = pepper
+	return &peppered
+}
+
+func (h *Hasher) Hash(plaintext string) ([]byte, error) {
+	if h.pepper == nil {
+		return h.current.Hash(plaintext)
+	}
+
+	peppered, err := h.pepper.apply(h.pepper.Current, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := h.current.Hash(peppered)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(pepperPrefix+h.pepper.Current), hash...), nil
+}
+
+func (h *Hasher) Verify(plaintext string, hash []byte) (bool, error) {
+	id, inner, peppered := splitPeppered(hash)
+	if peppered {
+		if h.pepper == nil {
+			return false, fmt.Errorf("hash uses pepper key %q but no pepper is configured", id)
+		}
+
+		var err error
+		plaintext, err = h.pepper.apply(id, plaintext)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	algorithm := h.algorithm(inner)
+	if algorithm == nil {
+		return false, ErrUnknownHash
+	}
+	return algorithm.Verify(plaintext, inner)
+}
+
+// NeedsRehash reports whether hash should be replaced by a fresh one from
+// the current algorithm and pepper key the next time the plaintext is known.
+func (h *Hasher) NeedsRehash(hash []byte) bool {
+	id, inner, peppered := splitPeppered(hash)
+	if h.pepper == nil && peppered {
+		return true
+	}
+	if h.pepper != nil && (!peppered || id != h.pepper.Current) {
+		return true
+	}
+
+	if !h.current.Owns(inner) {
+		return true
+	}
+	return h.current.Outdated(inner)
+}
+
+func (h *Hasher) algorithm(hash []byte) Algorithm {
+	if h.current.Owns(hash) {
+		return h.current
+	}
+	for _, algorithm := range h.legacy {
+		if algorithm.Owns(hash) {
+			return algorithm
+		}
+	}
+	return nil
+}
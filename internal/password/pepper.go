@@ -0,0 +1,84 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// pepperPrefix marks hashes of peppered passwords. It is followed by the key
+// id and the hash produced by the algorithm, e.g.
+// $pepper$id=2024-06$argon2id$v=19$...
+const pepperPrefix = "$pepper$id="
+
+const minPepperLength = 16
+
+// Pepper is a set of server-side secrets keyed by id. New hashes use the
+// current key; the others are kept to verify hashes until they are rehashed.
+type Pepper struct {
+	Current string
+	Keys    map[string][]byte
+}
+
+// ParsePepperKeys reads "id=base64key" pairs separated by commas or newlines.
+func ParsePepperKeys(s string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(field, "=")
+		if !ok || id == "" || strings.Contains(id, "$") {
+			return nil, fmt.Errorf("invalid pepper key entry, expected id=base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pepper key %s: %w", id, err)
+		}
+		if len(key) < minPepperLength {
+			return nil, fmt.Errorf("pepper key %s must be at least %d bytes", id, minPepperLength)
+		}
+		keys[id] = key
+	}
+
+	return keys, nil
+}
+
+func (p *Pepper) Validate() error {
+	if _, ok := p.Keys[p.Current]; !ok {
+		return fmt.Errorf("current pepper key %q is not among the keys", p.Current)
+	}
+	return nil
+}
+
+// apply mixes the key into the password. The MAC is base64-encoded so that it
+// never contains NUL bytes and stays within bcrypt's limit.
+func (p *Pepper) apply(id, plaintext string) (string, error) {
+	key, ok := p.Keys[id]
+	if !ok {
+		return "", fmt.Errorf("unknown pepper key %q", id)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(plaintext))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// splitPeppered returns the key id and the algorithm's hash of a peppered
+// hash, or ok == false for a hash without pepper.
+func splitPeppered(hash []byte) (id string, inner []byte, ok bool) {
+	rest, found := strings.CutPrefix(string(hash), pepperPrefix)
+	if !found {
+		return "", hash, false
+	}
+	i := strings.IndexByte(rest, '$')
+	if i < 0 {
+		return "", hash, false
+	}
+	return rest[:i], []byte(rest[i:]), true
+}
@@ -0,0 +1,119 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Policy describes what a new password must look like. Existing passwords are
+// never re-checked, so tightening it only affects password changes.
+type Policy struct {
+	MinLength int
+	// MaxLength is in bytes, as that is what hashers limit.
+	MaxLength     int
+	RequireLower  bool
+	RequireUpper  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// ForbidPersonal rejects passwords containing the user's email, its
+	// local part or their name.
+	ForbidPersonal bool
+	// Breached is consulted last; nil disables the check.
+	Breached *Corpus
+}
+
+// PolicyError lists every rule a password broke.
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return "password policy violation: " + strings.Join(e.Violations, "; ")
+}
+
+// minPersonalLength keeps short names and local parts such as "al" from
+// rejecting half of all passwords.
+const minPersonalLength = 3
+
+// Check returns a *PolicyError when password breaks the policy. email and name
+// belong to the account the password is for.
+func (p *Policy) Check(password, email, name string) error {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d bytes long", p.MaxLength))
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	if p.ForbidPersonal && containsPersonal(password, email, name) {
+		violations = append(violations, "must not contain your email or name")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return fmt.Errorf("failed to check breached passwords: %w", err)
+		}
+		if breached {
+			return &PolicyError{Violations: []string{"has appeared in a data breach, choose another one"}}
+		}
+	}
+
+	return nil
+}
+
+func containsPersonal(password, email, name string) bool {
+	password = strings.ToLower(password)
+
+	parts := strings.Fields(strings.ToLower(name))
+	if len(parts) > 1 {
+		parts = append(parts, strings.ToLower(name))
+	}
+	if email != "" {
+		email = strings.ToLower(email)
+		local, _, _ := strings.Cut(email, "@")
+		parts = append(parts, email, local)
+	}
+
+	for _, part := range parts {
+		if utf8.RuneCountInString(part) >= minPersonalLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+// Package queue drains outbox tables, such as queued emails and webhook
+// deliveries, from a pool of workers with leases and exponential retries.
+package queue
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/backoff"
+)
+
+// Queue claims batches of items with Claim and passes them to Handle. A
+// claim pushes the items' next attempt past Lease, so other workers and
+// replicas skip them while they are handled.
+type Queue[T any] struct {
+	Logger       *slog.Logger
+	Workers      int
+	BatchSize    int
+	PollInterval time.Duration
+	Lease        time.Duration
+	Claim        func(ctx context.Context, limit int, lease time.Duration) ([]T, error)
+	Handle       func(ctx context.Context, item T)
+}
+
+// Run handles items until ctx is done. Handle gets a context that is not
+// cancelled with ctx, so the outcome of an item in flight is still stored;
+// items of the batch not started yet are left to be claimed again once their
+// lease runs out. Callers bound the wait for Run to return on shutdown.
+func (q Queue[T]) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(q.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q Queue[T]) work(ctx context.Context) {
+	handleCtx := context.WithoutCancel(ctx)
+
+	for {
+		items, err := q.Claim(ctx, q.BatchSize, q.Lease)
+		if err != nil && ctx.Err() == nil {
+			q.Logger.Error("failed to claim batch", "error", err)
+		}
+
+		for _, item := range items {
+			if ctx.Err() != nil {
+				return
+			}
+			q.Handle(handleCtx, item)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if len(items) == q.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.PollInterval):
+		}
+	}
+}
+
+// Retry decides when a failed item is tried again and when it is given up.
+type Retry struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int
+}
+
+// Next takes the number of attempts made so far, the failed one included,
+// and returns when to try again and whether the item is dead instead.
+func (r Retry) Next(attempts int) (time.Time, bool) {
+	next := time.Now().Add(backoff.Exponential(attempts, r.BaseDelay, r.MaxDelay))
+	return next, attempts >= r.MaxAttempts
+}
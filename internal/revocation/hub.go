@@ -0,0 +1,54 @@
+package revocation
+
+import (
+	"sync"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+)
+
+const subscriberBuffer = 64
+
+// Hub fans revocation events out to every subscribed stream. Subscribers that
+// fall behind are dropped by closing their channel, so the client can
+// reconnect and resynchronise instead of silently missing events.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan storage.RevocationEvent]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan storage.RevocationEvent]struct{}),
+	}
+}
+
+func (h *Hub) Subscribe() (<-chan storage.RevocationEvent, func()) {
+	ch := make(chan storage.RevocationEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *Hub) Publish(event storage.RevocationEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
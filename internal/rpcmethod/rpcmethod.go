@@ -0,0 +1,58 @@
+// Package rpcmethod matches the method names used in the config and on API
+// keys against the full gRPC method of a call.
+package rpcmethod
+
+import (
+	"strings"
+
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+var userServicePrefix = "/" + pbuser.UserService_ServiceDesc.ServiceName + "/"
+
+// exempt lists the services that probes and tooling call without
+// credentials; no method name or "*" covers them.
+var exempt = []string{
+	healthpb.Health_ServiceDesc.ServiceName,
+	reflectionv1.ServerReflection_ServiceDesc.ServiceName,
+	reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName,
+}
+
+// Matches reports whether name refers to fullMethod. name is either a full
+// method ("/user.UserService/VerifyToken"), the short name of a UserService
+// method ("VerifyToken"), or "*" for every UserService method. Short names
+// never match methods of other services.
+func Matches(name, fullMethod string) bool {
+	if name == fullMethod {
+		return !IsExempt(fullMethod)
+	}
+	short, ok := strings.CutPrefix(fullMethod, userServicePrefix)
+	if !ok {
+		return false
+	}
+	return name == "*" || name == short
+}
+
+// MatchesAny reports whether any of names refers to fullMethod.
+func MatchesAny(names []string, fullMethod string) bool {
+	for _, name := range names {
+		if Matches(name, fullMethod) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExempt reports whether fullMethod belongs to the health or reflection
+// services.
+func IsExempt(fullMethod string) bool {
+	for _, service := range exempt {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
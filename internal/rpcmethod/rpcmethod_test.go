@@ -0,0 +1,28 @@
+package rpcmethod
+
+import "testing"
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		fullMethod string
+		want       bool
+	}{
+		{name: "/user.UserService/VerifyToken", fullMethod: "/user.UserService/VerifyToken", want: true},
+		{name: "VerifyToken", fullMethod: "/user.UserService/VerifyToken", want: true},
+		{name: "*", fullMethod: "/user.UserService/VerifyToken", want: true},
+		{name: "VerifyToken", fullMethod: "/user.UserService/VerifyTokens"},
+		{name: "Check", fullMethod: "/grpc.health.v1.Health/Check"},
+		{name: "*", fullMethod: "/grpc.health.v1.Health/Check"},
+		{name: "/grpc.health.v1.Health/Check", fullMethod: "/grpc.health.v1.Health/Check"},
+		{name: "*", fullMethod: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"},
+		{name: "*", fullMethod: "/other.Service/Method"},
+		{name: "/other.Service/Method", fullMethod: "/other.Service/Method", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := Matches(tt.name, tt.fullMethod); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.name, tt.fullMethod, got, tt.want)
+		}
+	}
+}
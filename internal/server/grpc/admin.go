@@ -0,0 +1,405 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func (s *Server) SuspendUser(ctx context.Context, request *pbuser.SuspendUserRequest) (*pbuser.AdminUserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "suspend user")
+
+	admin, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Reason == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason must not be empty")
+	}
+
+	var until *time.Time
+	if request.Until != 0 {
+		t := time.Unix(request.Until, 0)
+		if !t.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "until must be in the future")
+		}
+		until = &t
+	}
+
+	user, err := s.userByID(ctx, logg, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Suspend(admin.ID, request.Reason, until)
+
+	err = s.updateUser(ctx, logg, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditUserSuspended,
+		ActorID:      &admin.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+		Details:      map[string]any{"reason": request.Reason, "until": request.Until},
+	})
+
+	revoked, err := s.storage.DeleteAllTokensForUser(ctx, user.ID)
+	if err != nil {
+		logg.Error("failed to delete tokens for user", "user_id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	for scope, count := range revoked {
+		metrics.TokensRevoked(scope, metrics.TokenRevoked, count)
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditTokensRevoked,
+		ActorID:      &admin.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+		Details:      map[string]any{"reason": "suspended"},
+	})
+
+	logg.Info("user suspended", "user_id", user.ID, "admin_id", admin.ID)
+	return userToAdminUserMessage(user), nil
+}
+
+func (s *Server) UnsuspendUser(
+	ctx context.Context,
+	request *pbuser.UnsuspendUserRequest,
+) (*pbuser.AdminUserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "unsuspend user")
+
+	admin, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userByID(ctx, logg, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Unsuspend()
+
+	err = s.updateUser(ctx, logg, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditUserUnsuspended,
+		ActorID:      &admin.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+	})
+
+	logg.Info("user unsuspended", "user_id", user.ID, "admin_id", admin.ID)
+	return userToAdminUserMessage(user), nil
+}
+
+func (s *Server) GetUser(ctx context.Context, request *pbuser.GetUserRequest) (*pbuser.AdminUserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "get user")
+
+	_, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userByID(ctx, logg, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Permissions, err = s.storage.GetAllUserPermissions(ctx, user.ID)
+	if err != nil {
+		logg.Error("failed to get user permissions", "user_id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return userToAdminUserMessage(user), nil
+}
+
+func (s *Server) ListUsers(ctx context.Context, request *pbuser.ListUsersRequest) (*pbuser.ListUsersResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "list users")
+
+	_, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := storage.UserFilter{
+		EmailPrefix: request.EmailPrefix,
+		Activated:   request.Activated,
+		Suspended:   request.Suspended,
+		Permission:  request.Permission,
+		Descending:  request.Descending,
+	}
+
+	switch request.Sort {
+	case pbuser.UserSortField_USER_SORT_FIELD_CREATED_AT:
+		filter.Sort = storage.UserSortCreatedAt
+	case pbuser.UserSortField_USER_SORT_FIELD_EMAIL:
+		filter.Sort = storage.UserSortEmail
+	default:
+		filter.Sort = storage.UserSortID
+	}
+
+	if request.CreatedAfter != 0 {
+		t := time.Unix(request.CreatedAfter, 0)
+		filter.CreatedAfter = &t
+	}
+	if request.CreatedBefore != 0 {
+		t := time.Unix(request.CreatedBefore, 0)
+		filter.CreatedBefore = &t
+	}
+
+	pageSize := int(request.PageSize)
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page size must be between 0 and %d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+	// One extra row tells whether another page follows.
+	filter.Limit = pageSize + 1
+
+	if request.PageToken != "" {
+		filter.After, err = decodeUserCursor(request.PageToken, filter.Sort, filter.Descending)
+		if err != nil {
+			logg.Warn("invalid page token", "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	users, err := s.storage.ListUsers(ctx, filter)
+	if err != nil {
+		logg.Error("failed to list users", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	response := &pbuser.ListUsersResponse{}
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		response.NextPageToken = encodeUserCursor(users[pageSize-1], filter.Sort, filter.Descending)
+	}
+
+	response.Users = make([]*pbuser.AdminUserMessage, 0, len(users))
+	for _, user := range users {
+		response.Users = append(response.Users, userToAdminUserMessage(user))
+	}
+
+	return response, nil
+}
+
+func (s *Server) DeleteUser(ctx context.Context, request *pbuser.DeleteUserRequest) (*pbuser.DeleteUserResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "delete user")
+
+	admin, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userByID(ctx, logg, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.DeleteUser(ctx, user.ID, s.webhooks.Deliveries(storage.WebhookUserDeleted, user, nil)...)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		logg.Error("failed to delete user", "user_id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditUserDeleted,
+		ActorID:      &admin.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+		Details:      map[string]any{"email": user.Email},
+	})
+
+	return &pbuser.DeleteUserResponse{}, nil
+}
+
+func (s *Server) GrantPermissions(
+	ctx context.Context,
+	request *pbuser.ChangePermissionsRequest,
+) (*pbuser.AdminUserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "grant permissions")
+
+	return s.changePermissions(ctx, logg, request, storage.AuditPermissionsGranted, s.storage.AddPermission)
+}
+
+func (s *Server) RevokePermissions(
+	ctx context.Context,
+	request *pbuser.ChangePermissionsRequest,
+) (*pbuser.AdminUserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "revoke permissions")
+
+	return s.changePermissions(ctx, logg, request, storage.AuditPermissionsRevoked, s.storage.RemovePermission)
+}
+
+func (s *Server) changePermissions(
+	ctx context.Context,
+	logg *slog.Logger,
+	request *pbuser.ChangePermissionsRequest,
+	event string,
+	change func(ctx context.Context, userID int64, codes ...string) error,
+) (*pbuser.AdminUserMessage, error) {
+	admin, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.Permissions) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "permissions must not be empty")
+	}
+
+	user, err := s.userByID(ctx, logg, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = change(ctx, user.ID, request.Permissions...)
+	var unknown *storage.UnknownPermissionsError
+	if errors.As(err, &unknown) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(unknown.Codes))
+		for _, code := range unknown.Codes {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       "permissions",
+				Description: fmt.Sprintf("unknown permission %q", code),
+			})
+		}
+
+		logg.Warn("unknown permissions", "permissions", unknown.Codes)
+		return nil, badRequest(violations)
+	}
+	if err != nil {
+		logg.Error("failed to change permissions", "user_id", user.ID, "error", err)
+		s.audit.Record(ctx, storage.AuditEvent{
+			Event:        event,
+			ActorID:      &admin.ID,
+			TargetUserID: &user.ID,
+			Outcome:      storage.AuditFailure,
+			Details:      map[string]any{"permissions": request.Permissions},
+		})
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        event,
+		ActorID:      &admin.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+		Details:      map[string]any{"permissions": request.Permissions},
+	})
+
+	user.Permissions, err = s.storage.GetAllUserPermissions(ctx, user.ID)
+	if err != nil {
+		logg.Error("failed to get user permissions", "user_id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return userToAdminUserMessage(user), nil
+}
+
+// requireAdmin authenticates the caller from the "authorization: Bearer <token>"
+// metadata and checks that it holds the users:admin permission.
+func (s *Server) requireAdmin(ctx context.Context, logg *slog.Logger) (*storage.User, error) {
+	var token string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		token, _ = strings.CutPrefix(values[0], "Bearer ")
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+
+	user, err := s.userForAuthToken(ctx, logg, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.Permissions.Include(storage.PermissionUsersAdmin) {
+		logg.Warn("admin permission required", "user_id", user.ID)
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	return user, nil
+}
+
+func (s *Server) userByID(ctx context.Context, logg *slog.Logger, id int64) (*storage.User, error) {
+	user, err := s.storage.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		logg.Error("failed to get user by id", "user_id", id, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return user, nil
+}
+
+func (s *Server) updateUser(ctx context.Context, logg *slog.Logger, user *storage.User) error {
+	err := s.storage.UpdateUser(ctx, user)
+	if err != nil {
+		if errors.Is(err, storage.ErrEditConflict) {
+			logg.Warn("edit conflict", "user_id", user.ID)
+			return status.Error(codes.Aborted, "edit conflict")
+		}
+		logg.Error("failed to update user", "user_id", user.ID, "error", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	return nil
+}
+
+func userToAdminUserMessage(user *storage.User) *pbuser.AdminUserMessage {
+	message := &pbuser.AdminUserMessage{
+		User:               userToUserMessage(user),
+		Suspended:          user.IsSuspended(time.Now()),
+		Version:            int32(user.Version),
+		EmailUndeliverable: user.EmailUndeliverable,
+	}
+
+	if user.SuspendedAt != nil {
+		message.Suspension = &pbuser.Suspension{
+			Reason:      user.SuspendedReason,
+			SuspendedAt: user.SuspendedAt.Unix(),
+		}
+		if user.SuspendedBy != nil {
+			message.Suspension.SuspendedBy = *user.SuspendedBy
+		}
+		if user.SuspendedUntil != nil {
+			message.Suspension.Until = user.SuspendedUntil.Unix()
+		}
+	}
+
+	return message
+}
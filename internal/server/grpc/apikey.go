@@ -0,0 +1,173 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateAPIKey(
+	ctx context.Context,
+	request *pbuser.CreateAPIKeyRequest,
+) (*pbuser.CreateAPIKeyResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "create api key")
+
+	admin, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, method := range request.Methods {
+		if !IsServiceMethod(method) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown method %q", method)
+		}
+	}
+
+	var expiry *time.Time
+	if request.Expiry != 0 {
+		t := time.Unix(request.Expiry, 0)
+		if !t.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "expiry must be in the future")
+		}
+		expiry = &t
+	}
+
+	key, err := storage.GenerateAPIKey(request.Name, request.Methods, expiry, admin.ID)
+	if err != nil {
+		logg.Error("failed to generate api key", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.validator.Validate(key)
+	if err != nil {
+		return nil, validationError(logg, err)
+	}
+
+	err = s.storage.InsertAPIKey(ctx, key)
+	if err != nil {
+		logg.Error("failed to insert api key", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:   storage.AuditAPIKeyCreated,
+		ActorID: &admin.ID,
+		Outcome: storage.AuditSuccess,
+		Details: map[string]any{"key_id": key.ID, "name": key.Name, "methods": key.Methods},
+	})
+
+	logg.Info("api key created", "key_id", key.ID, "admin_id", admin.ID)
+	return &pbuser.CreateAPIKeyResponse{
+		ApiKey: apiKeyToMessage(key),
+		Key:    key.Plaintext,
+	}, nil
+}
+
+func (s *Server) ListAPIKeys(
+	ctx context.Context,
+	request *pbuser.ListAPIKeysRequest,
+) (*pbuser.ListAPIKeysResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "list api keys")
+
+	_, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.storage.ListAPIKeys(ctx, request.IncludeRevoked)
+	if err != nil {
+		logg.Error("failed to list api keys", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	response := &pbuser.ListAPIKeysResponse{
+		ApiKeys: make([]*pbuser.APIKeyMessage, 0, len(keys)),
+	}
+	for _, key := range keys {
+		response.ApiKeys = append(response.ApiKeys, apiKeyToMessage(key))
+	}
+
+	return response, nil
+}
+
+func (s *Server) RevokeAPIKey(ctx context.Context, request *pbuser.RevokeAPIKeyRequest) (*pbuser.APIKeyMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "revoke api key")
+
+	admin, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.storage.RevokeAPIKey(ctx, request.Id)
+	if err != nil {
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			return nil, status.Error(codes.NotFound, "api key not found")
+		}
+		logg.Error("failed to revoke api key", "key_id", request.Id, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:   storage.AuditAPIKeyRevoked,
+		ActorID: &admin.ID,
+		Outcome: storage.AuditSuccess,
+		Details: map[string]any{"key_id": key.ID, "name": key.Name},
+	})
+
+	logg.Info("api key revoked", "key_id", key.ID, "admin_id", admin.ID)
+	return apiKeyToMessage(key), nil
+}
+
+// IsServiceMethod accepts "*" and the full or short name of any
+// UserService method.
+func IsServiceMethod(method string) bool {
+	if method == "*" {
+		return true
+	}
+
+	name := strings.TrimPrefix(method, "/"+pbuser.UserService_ServiceDesc.ServiceName+"/")
+	if strings.Contains(name, "/") {
+		return false
+	}
+	for _, desc := range pbuser.UserService_ServiceDesc.Methods {
+		if desc.MethodName == name {
+			return true
+		}
+	}
+	for _, desc := range pbuser.UserService_ServiceDesc.Streams {
+		if desc.StreamName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func apiKeyToMessage(key *storage.APIKey) *pbuser.APIKeyMessage {
+	message := &pbuser.APIKeyMessage{
+		Id:        key.ID,
+		CreatedAt: key.CreatedAt.Unix(),
+		Name:      key.Name,
+		Methods:   key.Methods,
+	}
+	if key.CreatedBy != nil {
+		message.CreatedBy = *key.CreatedBy
+	}
+	if key.Expiry != nil {
+		message.Expiry = key.Expiry.Unix()
+	}
+	if key.LastUsedAt != nil {
+		message.LastUsedAt = key.LastUsedAt.Unix()
+	}
+	if key.RevokedAt != nil {
+		message.RevokedAt = key.RevokedAt.Unix()
+	}
+
+	return message
+}
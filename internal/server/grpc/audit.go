@@ -0,0 +1,114 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func (s *Server) ListAuditEvents(
+	ctx context.Context,
+	request *pbuser.ListAuditEventsRequest,
+) (*pbuser.ListAuditEventsResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "list audit events")
+
+	_, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := storage.AuditFilter{
+		Event:        request.Event,
+		ActorID:      request.ActorId,
+		TargetUserID: request.TargetUserId,
+		Outcome:      request.Outcome,
+	}
+	if request.CreatedAfter != 0 {
+		t := time.Unix(request.CreatedAfter, 0)
+		filter.CreatedAfter = &t
+	}
+	if request.CreatedBefore != 0 {
+		t := time.Unix(request.CreatedBefore, 0)
+		filter.CreatedBefore = &t
+	}
+
+	pageSize := int(request.PageSize)
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page size must be between 0 and %d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+	filter.Limit = pageSize + 1
+
+	if request.PageToken != "" {
+		filter.BeforeID, err = decodeIDCursor(request.PageToken)
+		if err != nil {
+			logg.Warn("invalid page token", "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	events, err := s.storage.ListAuditEvents(ctx, filter)
+	if err != nil {
+		logg.Error("failed to list audit events", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	response := &pbuser.ListAuditEventsResponse{}
+	if len(events) > pageSize {
+		events = events[:pageSize]
+		response.NextPageToken = encodeIDCursor(events[pageSize-1].ID)
+	}
+
+	response.Events = make([]*pbuser.AuditEvent, 0, len(events))
+	for _, event := range events {
+		response.Events = append(response.Events, auditEventToMessage(event))
+	}
+
+	return response, nil
+}
+
+func auditEventToMessage(event *storage.AuditEvent) *pbuser.AuditEvent {
+	message := &pbuser.AuditEvent{
+		Id:        event.ID,
+		CreatedAt: event.CreatedAt.Unix(),
+		Event:     event.Event,
+		PeerIp:    event.PeerIP,
+		Method:    event.Method,
+		Outcome:   event.Outcome,
+	}
+	if event.ActorID != nil {
+		message.ActorId = *event.ActorID
+	}
+	if event.TargetUserID != nil {
+		message.TargetUserId = *event.TargetUserID
+	}
+
+	details, err := structpb.NewStruct(event.Details)
+	if err == nil {
+		message.Details = details
+	}
+
+	return message
+}
+
+func encodeIDCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeIDCursor(token string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+)
+
+// userCursor is the opaque page token of ListUsers. It pins the sort order it
+// was issued for, so a token can't be replayed against a different ordering.
+type userCursor struct {
+	Sort       string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	ID         int64  `json:"i"`
+	CreatedAt  int64  `json:"c,omitempty"`
+	Email      string `json:"e,omitempty"`
+}
+
+func encodeUserCursor(user *storage.User, sort string, descending bool) string {
+	cursor := userCursor{
+		Sort:       sort,
+		Descending: descending,
+		ID:         user.ID,
+	}
+	switch sort {
+	case storage.UserSortCreatedAt:
+		cursor.CreatedAt = user.CreatedAt.Unix()
+	case storage.UserSortEmail:
+		cursor.Email = user.Email
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(token, sort string, descending bool) (*storage.User, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor userCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.Sort != sort || cursor.Descending != descending {
+		return nil, errors.New("page token was issued for a different sort order")
+	}
+
+	return &storage.User{
+		ID:        cursor.ID,
+		CreatedAt: time.Unix(cursor.CreatedAt, 0),
+		Email:     cursor.Email,
+	}, nil
+}
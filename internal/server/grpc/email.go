@@ -0,0 +1,103 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ListEmails(ctx context.Context, request *pbuser.ListEmailsRequest) (*pbuser.ListEmailsResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "list emails")
+
+	_, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := storage.EmailFilter{
+		Status: request.Status,
+	}
+	if filter.Status == "" {
+		filter.Status = storage.EmailFailed
+	}
+
+	pageSize := int(request.PageSize)
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page size must be between 0 and %d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+	filter.Limit = pageSize + 1
+
+	if request.PageToken != "" {
+		filter.BeforeID, err = decodeIDCursor(request.PageToken)
+		if err != nil {
+			logg.Warn("invalid page token", "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	emails, err := s.storage.ListEmails(ctx, filter)
+	if err != nil {
+		logg.Error("failed to list emails", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	response := &pbuser.ListEmailsResponse{}
+	if len(emails) > pageSize {
+		emails = emails[:pageSize]
+		response.NextPageToken = encodeIDCursor(emails[pageSize-1].ID)
+	}
+
+	response.Emails = make([]*pbuser.EmailMessage, 0, len(emails))
+	for _, email := range emails {
+		response.Emails = append(response.Emails, emailToMessage(email))
+	}
+
+	return response, nil
+}
+
+func (s *Server) RetryEmail(ctx context.Context, request *pbuser.RetryEmailRequest) (*pbuser.EmailMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "retry email")
+
+	_, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := s.storage.RetryEmail(ctx, request.Id)
+	if err != nil {
+		if errors.Is(err, storage.ErrEmailNotFound) {
+			return nil, status.Error(codes.NotFound, "failed email not found")
+		}
+		logg.Error("failed to retry email", "email_id", request.Id, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return emailToMessage(email), nil
+}
+
+func emailToMessage(email *storage.Email) *pbuser.EmailMessage {
+	message := &pbuser.EmailMessage{
+		Id:            email.ID,
+		CreatedAt:     email.CreatedAt.Unix(),
+		Recipient:     email.Recipient,
+		Template:      email.Template,
+		Locale:        email.Locale,
+		Status:        email.Status,
+		Attempts:      int32(email.Attempts),
+		NextAttemptAt: email.NextAttemptAt.Unix(),
+		LastError:     email.LastError,
+	}
+	if email.SentAt != nil {
+		message.SentAt = email.SentAt.Unix()
+	}
+
+	return message
+}
@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/AndreyChufelin/movies-auth/internal/apikey"
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) WatchRevocations(
+	_ *pbuser.WatchRevocationsRequest,
+	stream pbuser.UserService_WatchRevocationsServer,
+) error {
+	logg := logging.FromContext(stream.Context()).With("handler", "watch revocations")
+
+	if !isServiceCaller(stream.Context()) {
+		_, err := s.requireAdmin(stream.Context(), logg)
+		if err != nil {
+			return err
+		}
+	}
+
+	events, unsubscribe := s.revocations.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event, ok := <-events:
+			if !ok {
+				logg.Warn("subscriber dropped, too slow")
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow")
+			}
+
+			err := stream.Send(revocationToMessage(event))
+			if err != nil {
+				logg.Warn("failed to send revocation event", "error", err)
+				return err
+			}
+		}
+	}
+}
+
+func revocationToMessage(event storage.RevocationEvent) *pbuser.RevocationEvent {
+	var eventType pbuser.RevocationType
+	switch event.Type {
+	case storage.RevocationTokensRevoked:
+		eventType = pbuser.RevocationType_REVOCATION_TYPE_TOKENS_REVOKED
+	case storage.RevocationUserDeactivated:
+		eventType = pbuser.RevocationType_REVOCATION_TYPE_USER_DEACTIVATED
+	case storage.RevocationUserDeleted:
+		eventType = pbuser.RevocationType_REVOCATION_TYPE_USER_DELETED
+	case storage.RevocationUserSuspended:
+		eventType = pbuser.RevocationType_REVOCATION_TYPE_USER_SUSPENDED
+	case storage.RevocationPermissionsChanged:
+		eventType = pbuser.RevocationType_REVOCATION_TYPE_PERMISSIONS_CHANGED
+	case storage.RevocationResync:
+		eventType = pbuser.RevocationType_REVOCATION_TYPE_RESYNC
+	}
+
+	return &pbuser.RevocationEvent{
+		Type:   eventType,
+		UserId: event.UserID,
+		Scope:  event.Scope,
+	}
+}
+
+// isServiceCaller reports whether the call was authenticated as another
+// service: with an API key the interceptor already allowed for the method,
+// or with a client certificate that verified against the configured CA.
+func isServiceCaller(ctx context.Context) bool {
+	if _, ok := apikey.FromContext(ctx); ok {
+		return true
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	return ok && len(info.State.VerifiedChains) > 0
+}
@@ -10,53 +10,165 @@ import (
 	"time"
 
 	"github.com/AndreyChufelin/movies-api/pkg/validator"
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+	"github.com/AndreyChufelin/movies-auth/internal/password"
 	"github.com/AndreyChufelin/movies-auth/internal/storage"
 	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	pbuser.UnimplementedUserServiceServer
-	logger    *slog.Logger
-	server    *grpc.Server
-	port      string
-	storage   Storage
-	validator *validator.Validator
-	mailer    Mailer
-	wg        sync.WaitGroup
+	logger      *slog.Logger
+	server      *grpc.Server
+	address     string
+	storage     Storage
+	validator   *validator.Validator
+	revocations Revocations
+	audit       Auditor
+	webhooks    Webhooks
+	passwords   PasswordPolicy
+	hasher      PasswordHasher
+	health      Health
+	done        chan struct{}
+	stopOnce    sync.Once
+
+	ttlMu     sync.RWMutex
+	tokenTTLs TokenTTLs
 }
 
 type Storage interface {
-	InsertUser(user *storage.User) error
-	GetUserByEmail(email string) (*storage.User, error)
-	UpdateUser(user *storage.User) error
-	NewToken(userID int64, ttl time.Duration, scope string) (*storage.Token, error)
-	GetUserForToken(scope, token string) (*storage.User, error)
-	GetAllUserPermissions(userID int64) (storage.Permissions, error)
-	DeleteToAllTokensForUser(scope string, userID int64) error
-	AddPermission(userID int64, codes ...string) error
+	InsertUser(ctx context.Context, user *storage.User, outbox ...storage.OutboxMessage) error
+	GetUserByEmail(ctx context.Context, email string) (*storage.User, error)
+	GetUserByID(ctx context.Context, id int64) (*storage.User, error)
+	ListUsers(ctx context.Context, filter storage.UserFilter) ([]*storage.User, error)
+	UpdateUser(ctx context.Context, user *storage.User, outbox ...storage.OutboxMessage) error
+	DeleteUser(ctx context.Context, id int64, outbox ...storage.OutboxMessage) error
+	NewToken(ctx context.Context, userID int64, ttl time.Duration, scope string) (*storage.Token, error)
+	GetUserForToken(ctx context.Context, scope, token string) (*storage.User, error)
+	GetUsersForTokens(ctx context.Context, scope string, tokens []string) (map[string]*storage.User, error)
+	GetAllUserPermissions(ctx context.Context, userID int64) (storage.Permissions, error)
+	DeleteToAllTokensForUser(ctx context.Context, scope string, userID int64) (int, error)
+	DeleteAllTokensForUser(ctx context.Context, userID int64) (map[string]int, error)
+	AddPermission(ctx context.Context, userID int64, codes ...string) error
+	RemovePermission(ctx context.Context, userID int64, codes ...string) error
+	ListAuditEvents(ctx context.Context, filter storage.AuditFilter) ([]*storage.AuditEvent, error)
+	ListEmails(ctx context.Context, filter storage.EmailFilter) ([]*storage.Email, error)
+	RetryEmail(ctx context.Context, id int64) (*storage.Email, error)
+	AddSuppressions(ctx context.Context, suppressions []*storage.Suppression) error
+	IsEmailSuppressed(ctx context.Context, email string) (bool, error)
+	ListSuppressions(ctx context.Context, filter storage.SuppressionFilter) ([]*storage.Suppression, error)
+	DeleteSuppression(ctx context.Context, email string) error
+	InsertAPIKey(ctx context.Context, key *storage.APIKey) error
+	ListAPIKeys(ctx context.Context, includeRevoked bool) ([]*storage.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (*storage.APIKey, error)
+}
+
+type Revocations interface {
+	Subscribe() (<-chan storage.RevocationEvent, func())
+}
+
+type Auditor interface {
+	Record(ctx context.Context, event storage.AuditEvent)
+}
+
+type Webhooks interface {
+	Deliveries(event string, user *storage.User, data map[string]any) []storage.OutboxMessage
+}
+
+// Health is the grpc.health.v1 service. Shutdown makes it report
+// NOT_SERVING for good.
+type Health interface {
+	healthpb.HealthServer
+	Shutdown()
+}
+
+type PasswordPolicy interface {
+	Check(password, email, name string) error
 }
 
-type Mailer interface {
-	Send(recipient, templateFile string, data interface{}) error
+type PasswordHasher interface {
+	Hash(plaintext string) ([]byte, error)
+	Verify(plaintext string, hash []byte) (bool, error)
+	NeedsRehash(hash []byte) bool
 }
 
-func NewGRPC(logger *slog.Logger, storage Storage, mailer Mailer, port string) *Server {
-	grpcServer := grpc.NewServer()
+func NewGRPC(
+	logger *slog.Logger,
+	storage Storage,
+	revocations Revocations,
+	audit Auditor,
+	webhooks Webhooks,
+	passwords PasswordPolicy,
+	hasher PasswordHasher,
+	health Health,
+	tokenTTLs TokenTTLs,
+	address string,
+	opts ...grpc.ServerOption,
+) *Server {
+	// Interceptors passed in opts are chained after logging and metrics, so
+	// rejected calls are still logged and counted.
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			logging.UnaryServerInterceptor(logger),
+			metrics.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			logging.StreamServerInterceptor(logger),
+			metrics.StreamServerInterceptor(),
+		),
+	}, opts...)...)
 	return &Server{
-		logger:  logger,
-		storage: storage,
-		mailer:  mailer,
-		server:  grpcServer,
-		port:    port,
+		logger:      logger,
+		storage:     storage,
+		revocations: revocations,
+		audit:       audit,
+		webhooks:    webhooks,
+		passwords:   passwords,
+		hasher:      hasher,
+		health:      health,
+		tokenTTLs:   tokenTTLs,
+		server:      grpcServer,
+		address:     address,
+		done:        make(chan struct{}),
 	}
 }
 
+type TokenTTLs struct {
+	Activation     time.Duration
+	Authentication time.Duration
+}
+
+// SetTokenTTLs changes the lifetime of tokens issued from now on; existing
+// tokens keep their expiry.
+func (s *Server) SetTokenTTLs(ttls TokenTTLs) {
+	s.ttlMu.Lock()
+	s.tokenTTLs = ttls
+	s.ttlMu.Unlock()
+}
+
+func (s *Server) ttls() TokenTTLs {
+	s.ttlMu.RLock()
+	defer s.ttlMu.RUnlock()
+	return s.tokenTTLs
+}
+
+// EnableReflection registers the gRPC reflection service so tools such as
+// grpcurl can discover the API. It must be called before Start.
+func (s *Server) EnableReflection() {
+	reflection.Register(s.server)
+}
+
 func (s *Server) Start() error {
-	l, err := net.Listen("tcp", fmt.Sprintf(":%s", s.port))
+	l, err := net.Listen("tcp", s.address)
 	if err != nil {
 		return fmt.Errorf("failed start tcp server: %w", err)
 	}
@@ -68,6 +180,7 @@ func (s *Server) Start() error {
 
 	s.logger.Info("grpc server started", slog.String("addr", l.Addr().String()))
 	pbuser.RegisterUserServiceServer(s.server, s)
+	healthpb.RegisterHealthServer(s.server, s.health)
 
 	if err := s.server.Serve(l); err != nil {
 		return fmt.Errorf("failed to start grpc server: %w", err)
@@ -78,10 +191,10 @@ func (s *Server) Start() error {
 
 func (s *Server) Stop(ctx context.Context) error {
 	s.logger.Info("stopping grpc server")
+	s.health.Shutdown()
+	s.stopOnce.Do(func() { close(s.done) })
 	done := make(chan struct{})
 
-	s.wg.Wait()
-
 	go func() {
 		s.server.GracefulStop()
 		close(done)
@@ -98,41 +211,51 @@ func (s *Server) Stop(ctx context.Context) error {
 	}
 }
 
-func (s *Server) background(fn func()) {
-	s.wg.Add(1)
-	go func() {
-		defer s.wg.Done()
-
-		defer func() {
-			if err := recover(); err != nil {
-				s.logger.Error("panic recovered", err)
-			}
-		}()
-
-		fn()
-	}()
-}
-
 func validationError(logger *slog.Logger, err error) error {
 	var vErr *validator.ValidationErrors
 	if errors.As(err, &vErr) {
-		st := status.New(codes.InvalidArgument, "validation error")
-
-		br := &errdetails.BadRequest{}
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(vErr.Errors))
 		for _, e := range vErr.Errors {
-			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
 				Field:       e.Field,
 				Description: e.Message,
 			})
 		}
 
-		st, err := st.WithDetails(br)
-		if err != nil {
-			panic(fmt.Sprintf("Unexpected error attaching metadata: %v", err))
-		}
-
 		logger.Warn("validation error", "error", vErr.Error())
-		return st.Err()
+		return badRequest(violations)
 	}
 	return status.Error(codes.InvalidArgument, "validation error")
 }
+
+// passwordError reports password policy violations on field the same way
+// validationError reports struct validation failures.
+func passwordError(logger *slog.Logger, field string, err error) error {
+	var pErr *password.PolicyError
+	if errors.As(err, &pErr) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(pErr.Violations))
+		for _, v := range pErr.Violations {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: v,
+			})
+		}
+
+		logger.Warn("password policy violation", "violations", pErr.Violations)
+		return badRequest(violations)
+	}
+
+	logger.Error("failed to check password policy", "error", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func badRequest(violations []*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, "validation error")
+
+	st, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		panic(fmt.Sprintf("Unexpected error attaching metadata: %v", err))
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,163 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxBounces = 1000
+
+// ReportBounces ingests delivery notifications. Provider webhooks are
+// expected to be adapted to this format by a small relay, which
+// authenticates as a service with an API key or a client certificate.
+func (s *Server) ReportBounces(
+	ctx context.Context,
+	request *pbuser.ReportBouncesRequest,
+) (*pbuser.ReportBouncesResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "report bounces")
+
+	if !isServiceCaller(ctx) {
+		logg.Warn("bounces reported without service credentials")
+		return nil, status.Error(codes.Unauthenticated, "api key or client certificate required")
+	}
+
+	if len(request.Bounces) > maxBounces {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d bounces per request", maxBounces)
+	}
+
+	var suppressions []*storage.Suppression
+	for _, bounce := range request.Bounces {
+		input := struct {
+			Email string `validate:"required,email"`
+		}{bounce.Email}
+
+		err := s.validator.Validate(input)
+		if err != nil {
+			return nil, validationError(logg, err)
+		}
+
+		var reason string
+		switch bounce.Type {
+		case pbuser.BounceType_BOUNCE_TYPE_HARD:
+			reason = storage.SuppressionBounce
+		case pbuser.BounceType_BOUNCE_TYPE_COMPLAINT:
+			reason = storage.SuppressionComplaint
+		case pbuser.BounceType_BOUNCE_TYPE_SOFT:
+			continue
+		default:
+			return nil, status.Error(codes.InvalidArgument, "unknown bounce type")
+		}
+
+		suppressions = append(suppressions, &storage.Suppression{
+			Email:  bounce.Email,
+			Reason: reason,
+			Detail: bounce.Detail,
+		})
+	}
+
+	if len(suppressions) > 0 {
+		err := s.storage.AddSuppressions(ctx, suppressions)
+		if err != nil {
+			logg.Error("failed to add suppressions", "error", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	return &pbuser.ReportBouncesResponse{Suppressed: int32(len(suppressions))}, nil
+}
+
+func (s *Server) ListSuppressions(
+	ctx context.Context,
+	request *pbuser.ListSuppressionsRequest,
+) (*pbuser.ListSuppressionsResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "list suppressions")
+
+	_, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := storage.SuppressionFilter{
+		EmailPrefix: request.EmailPrefix,
+	}
+
+	pageSize := int(request.PageSize)
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page size must be between 0 and %d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+	filter.Limit = pageSize + 1
+
+	if request.PageToken != "" {
+		after, err := base64.RawURLEncoding.DecodeString(request.PageToken)
+		if err != nil {
+			logg.Warn("invalid page token", "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		filter.AfterEmail = string(after)
+	}
+
+	suppressions, err := s.storage.ListSuppressions(ctx, filter)
+	if err != nil {
+		logg.Error("failed to list suppressions", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	response := &pbuser.ListSuppressionsResponse{}
+	if len(suppressions) > pageSize {
+		suppressions = suppressions[:pageSize]
+		last := suppressions[pageSize-1].Email
+		response.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(last))
+	}
+
+	response.Suppressions = make([]*pbuser.SuppressionMessage, 0, len(suppressions))
+	for _, suppression := range suppressions {
+		response.Suppressions = append(response.Suppressions, &pbuser.SuppressionMessage{
+			Email:     suppression.Email,
+			CreatedAt: suppression.CreatedAt.Unix(),
+			Reason:    suppression.Reason,
+			Detail:    suppression.Detail,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *Server) DeleteSuppression(
+	ctx context.Context,
+	request *pbuser.DeleteSuppressionRequest,
+) (*pbuser.DeleteSuppressionResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "delete suppression")
+
+	admin, err := s.requireAdmin(ctx, logg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.DeleteSuppression(ctx, request.Email)
+	if err != nil {
+		if errors.Is(err, storage.ErrSuppressionNotFound) {
+			return nil, status.Error(codes.NotFound, "suppression not found")
+		}
+		logg.Error("failed to delete suppression", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:   storage.AuditSuppressionDeleted,
+		ActorID: &admin.ID,
+		Outcome: storage.AuditSuccess,
+		Details: map[string]any{"email": request.Email},
+	})
+
+	return &pbuser.DeleteSuppressionResponse{}, nil
+}
@@ -3,25 +3,42 @@ package grpcserver
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 
+	"github.com/AndreyChufelin/movies-auth/internal/logging"
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
 	"github.com/AndreyChufelin/movies-auth/internal/storage"
 	pbuser "github.com/AndreyChufelin/movies-auth/pkg/pb/user"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-func (s *Server) Register(_ context.Context, request *pbuser.RegisterRequest) (*pbuser.UserMessage, error) {
-	logg := s.logger.With("handler", "register user")
-	logg.Info("REQUEST")
+const maxVerifyTokens = 100
+
+func (s *Server) Register(ctx context.Context, request *pbuser.RegisterRequest) (*pbuser.UserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "register user")
 
 	user := &storage.User{
 		Email:     request.Email,
 		Name:      request.Name,
 		Activated: false,
+		Locale:    request.Locale,
+	}
+	if user.Locale == "" {
+		user.Locale = storage.DefaultLocale
 	}
 	if request.Password != "" {
-		user.SetPassword(request.Password)
+		err := s.passwords.Check(request.Password, request.Email, request.Name)
+		if err != nil {
+			return nil, passwordError(logg, "Password", err)
+		}
+
+		err = s.setPassword(user, request.Password)
+		if err != nil {
+			logg.Error("failed to set password", "error", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
 	}
 
 	err := s.validator.Validate(user)
@@ -29,7 +46,29 @@ func (s *Server) Register(_ context.Context, request *pbuser.RegisterRequest) (*
 		return nil, validationError(logg, err)
 	}
 
-	err = s.storage.InsertUser(user)
+	// A suppressed address doesn't block registration; the account is
+	// flagged so operators can see why the welcome email never arrives.
+	user.EmailUndeliverable, err = s.storage.IsEmailSuppressed(ctx, user.Email)
+	if err != nil {
+		logg.Error("failed to check suppression list", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	token, err := storage.GenerateToken(0, s.ttls().Activation, storage.ScopeActivation)
+	if err != nil {
+		logg.Error("failed to generate new token", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	welcome := storage.NewEmail(user, "user_welcome.tmpl", map[string]any{
+		"activationToken":  token.Plaintext,
+		"activationExpiry": token.Expiry.UTC().Format("2006-01-02 15:04 MST"),
+	})
+
+	outbox := s.webhooks.Deliveries(storage.WebhookUserRegistered, user, nil)
+	outbox = append(outbox, token, welcome)
+
+	err = s.storage.InsertUser(ctx, user, outbox...)
 	if err != nil {
 		if errors.Is(err, storage.ErrDuplicateEmail) {
 			logg.Warn("email already exists", "email", user.Email)
@@ -39,42 +78,37 @@ func (s *Server) Register(_ context.Context, request *pbuser.RegisterRequest) (*
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	err = s.storage.AddPermission(user.ID, "movies:read")
-	if err != nil {
-		logg.Error("failed to add permission to user")
-		return nil, status.Error(codes.Internal, "internal error")
-	}
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditUserRegistered,
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+	})
 
-	token, err := s.storage.NewToken(user.ID, 3*24*time.Hour, storage.ScopeActivation)
+	err = s.storage.AddPermission(ctx, user.ID, "movies:read")
 	if err != nil {
-		logg.Error("failed to generate new token", "user_id", user.ID, "error", err)
+		logg.Error("failed to add permission to user")
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	s.background(func() {
-		data := map[string]interface{}{
-			"activationToken": token.Plaintext,
-			"userID":          user.ID,
-		}
-
-		err = s.mailer.Send(user.Email, "user_welcome.tmpl", data)
-		if err != nil {
-			logg.Error("failed to send email", "error", err)
-		}
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditPermissionsGranted,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+		Details:      map[string]any{"permissions": []string{"movies:read"}},
 	})
 
 	return userToUserMessage(user), nil
 }
 
-func (s *Server) Activated(_ context.Context, request *pbuser.ActivatedRequest) (*pbuser.UserMessage, error) {
-	logg := s.logger.With("handler", "activated")
-	logg.Info("REQUEST")
+func (s *Server) Activated(ctx context.Context, request *pbuser.ActivatedRequest) (*pbuser.UserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "activated")
 
 	if len(request.Token) != 26 {
 		return nil, status.Error(codes.InvalidArgument, "invalid token")
 	}
 
-	user, err := s.storage.GetUserForToken(storage.ScopeActivation, request.Token)
+	user, err := s.storage.GetUserForToken(ctx, storage.ScopeActivation, request.Token)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			logg.Warn("invalid or expired token")
@@ -86,7 +120,7 @@ func (s *Server) Activated(_ context.Context, request *pbuser.ActivatedRequest)
 
 	user.Activated = true
 
-	err = s.storage.UpdateUser(user)
+	err = s.storage.UpdateUser(ctx, user, s.webhooks.Deliveries(storage.WebhookUserActivated, user, nil)...)
 	if err != nil {
 		if errors.Is(err, storage.ErrEditConflict) {
 			logg.Warn("edit conflict")
@@ -96,25 +130,32 @@ func (s *Server) Activated(_ context.Context, request *pbuser.ActivatedRequest)
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	err = s.storage.DeleteToAllTokensForUser(storage.ScopeActivation, user.ID)
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditUserActivated,
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+	})
+
+	consumed, err := s.storage.DeleteToAllTokensForUser(ctx, storage.ScopeActivation, user.ID)
 	if err != nil {
 		logg.Error("failed to delete tokens for user", "user_id", user.ID, "error", err)
 		return nil, status.Error(codes.Internal, "internal error")
 	}
+	metrics.TokensRevoked(storage.ScopeActivation, metrics.TokenConsumed, consumed)
 
 	return userToUserMessage(user), nil
 }
 
 func (s *Server) Authentication(
-	_ context.Context,
+	ctx context.Context,
 	request *pbuser.AuthenticationRequest,
 ) (*pbuser.AuthenticationResponse, error) {
-	logg := s.logger.With("handler", "authentication")
-	logg.Info("REQUEST")
+	logg := logging.FromContext(ctx).With("handler", "authentication")
 
 	input := struct {
 		Email    string `validate:"required,email"`
-		Password string `validate:"required,gte=8,lte=72"`
+		Password string `validate:"required,lte=1024"`
 	}{request.Email, request.Password}
 
 	err := s.validator.Validate(input)
@@ -122,17 +163,23 @@ func (s *Server) Authentication(
 		return nil, validationError(logg, err)
 	}
 
-	user, err := s.storage.GetUserByEmail(request.Email)
+	user, err := s.storage.GetUserByEmail(ctx, request.Email)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			logg.Warn("user doesn't exist")
+			s.audit.Record(ctx, storage.AuditEvent{
+				Event:   storage.AuditUserLogin,
+				Outcome: storage.AuditFailure,
+				Details: map[string]any{"reason": "user_not_found", "email": request.Email},
+			})
+			metrics.LoginFailed(metrics.LoginUserNotFound)
 			return nil, status.Error(codes.InvalidArgument, "user not exist")
 		}
 		logg.Error("failed to get user by email", "error", err)
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	match, err := user.PasswordMatches(request.Password)
+	match, err := s.hasher.Verify(request.Password, user.PasswordHash)
 	if err != nil {
 		logg.Error("failed to match password", "id", user.ID, "error", err)
 		return nil, status.Error(codes.Internal, "internal error")
@@ -140,35 +187,298 @@ func (s *Server) Authentication(
 
 	if !match {
 		logg.Warn("invalid password", "id", user.ID)
+		s.audit.Record(ctx, storage.AuditEvent{
+			Event:        storage.AuditUserLogin,
+			TargetUserID: &user.ID,
+			Outcome:      storage.AuditFailure,
+			Details:      map[string]any{"reason": "invalid_password"},
+		})
+		metrics.LoginFailed(metrics.LoginInvalidPassword)
 		return nil, status.Error(codes.InvalidArgument, "invalid password")
 	}
 
-	token, err := s.storage.NewToken(user.ID, 24*time.Hour, storage.ScopeAuthentication)
+	if user.IsSuspended(time.Now()) {
+		logg.Warn("user is suspended", "id", user.ID)
+		s.audit.Record(ctx, storage.AuditEvent{
+			Event:        storage.AuditUserLogin,
+			TargetUserID: &user.ID,
+			Outcome:      storage.AuditFailure,
+			Details:      map[string]any{"reason": "suspended"},
+		})
+		metrics.LoginFailed(metrics.LoginSuspended)
+		return nil, status.Error(codes.PermissionDenied, "account suspended")
+	}
+
+	if s.hasher.NeedsRehash(user.PasswordHash) {
+		s.rehashPassword(ctx, logg, user, request.Password)
+	}
+
+	token, err := s.storage.NewToken(ctx, user.ID, s.ttls().Authentication, storage.ScopeAuthentication)
 	if err != nil {
 		logg.Error("failed te create new token", "error", err)
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditUserLogin,
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+	})
+	metrics.LoginSucceeded()
+
 	return &pbuser.AuthenticationResponse{
 		Token:  token.Plaintext,
 		Expiry: token.Expiry.Unix(),
 	}, nil
 }
 
-func (s *Server) VerifyToken(_ context.Context, request *pbuser.VerifyTokenRequest) (*pbuser.UserMessage, error) {
-	logg := s.logger.With("handler", "verify token")
-	logg.Info("REQUEST")
+// setPassword hashes plaintext into user.PasswordHash and keeps the
+// plaintext on the user for validation.
+func (s *Server) setPassword(user *storage.User, plaintext string) error {
+	hash, err := s.hasher.Hash(plaintext)
+	if err != nil {
+		return err
+	}
+	user.Password = &plaintext
+	user.PasswordHash = hash
+	return nil
+}
 
-	if request.Token == "" {
-		user := storage.AnonymousUser
-		return userToUserMessage(user), nil
+// rehashPassword upgrades a hash from an outdated algorithm or parameters.
+// Failing to do so doesn't fail the login; it is retried on the next one.
+func (s *Server) rehashPassword(ctx context.Context, logg *slog.Logger, user *storage.User, plaintext string) {
+	err := s.setPassword(user, plaintext)
+	if err != nil {
+		logg.Error("failed to rehash password", "id", user.ID, "error", err)
+		return
 	}
 
-	if len(request.Token) != 26 {
+	err = s.storage.UpdateUser(ctx, user)
+	if err != nil {
+		logg.Warn("failed to save rehashed password", "id", user.ID, "error", err)
+		return
+	}
+
+	logg.Info("password rehashed", "id", user.ID)
+}
+
+func (s *Server) ChangePassword(
+	ctx context.Context,
+	request *pbuser.ChangePasswordRequest,
+) (*pbuser.ChangePasswordResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "change password")
+
+	user, err := s.userForAuthToken(ctx, logg, request.Token)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAnonymous() {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	match, err := s.hasher.Verify(request.CurrentPassword, user.PasswordHash)
+	if err != nil {
+		logg.Error("failed to match password", "id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if !match {
+		logg.Warn("invalid password", "id", user.ID)
+		s.audit.Record(ctx, storage.AuditEvent{
+			Event:        storage.AuditPasswordChanged,
+			ActorID:      &user.ID,
+			TargetUserID: &user.ID,
+			Outcome:      storage.AuditFailure,
+			Details:      map[string]any{"reason": "invalid_password"},
+		})
+		return nil, status.Error(codes.InvalidArgument, "invalid password")
+	}
+
+	err = s.passwords.Check(request.NewPassword, user.Email, user.Name)
+	if err != nil {
+		return nil, passwordError(logg, "NewPassword", err)
+	}
+
+	err = s.setPassword(user, request.NewPassword)
+	if err != nil {
+		logg.Error("failed to set password", "id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.validator.Validate(user)
+	if err != nil {
+		return nil, validationError(logg, err)
+	}
+
+	err = s.updateUser(ctx, logg, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditPasswordChanged,
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+	})
+
+	revoked, err := s.storage.DeleteToAllTokensForUser(ctx, storage.ScopeAuthentication, user.ID)
+	if err != nil {
+		logg.Error("failed to delete tokens for user", "user_id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	metrics.TokensRevoked(storage.ScopeAuthentication, metrics.TokenRevoked, revoked)
+
+	s.audit.Record(ctx, storage.AuditEvent{
+		Event:        storage.AuditTokensRevoked,
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Outcome:      storage.AuditSuccess,
+		Details:      map[string]any{"scope": storage.ScopeAuthentication, "reason": "password_changed"},
+	})
+
+	return &pbuser.ChangePasswordResponse{}, nil
+}
+
+func (s *Server) UpdateProfile(
+	ctx context.Context,
+	request *pbuser.UpdateProfileRequest,
+) (*pbuser.UserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "update profile")
+
+	user, err := s.userForAuthToken(ctx, logg, request.Token)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAnonymous() {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	previousEmail := user.Email
+	if request.Name != nil {
+		user.Name = *request.Name
+	}
+	if request.Email != nil {
+		user.Email = *request.Email
+	}
+	if request.Locale != nil {
+		user.Locale = *request.Locale
+	}
+
+	input := struct {
+		Name   string `validate:"required,lte=500"`
+		Email  string `validate:"required,email"`
+		Locale string `validate:"required,bcp47_language_tag"`
+	}{user.Name, user.Email, user.Locale}
+
+	err = s.validator.Validate(input)
+	if err != nil {
+		return nil, validationError(logg, err)
+	}
+
+	var outbox []storage.OutboxMessage
+	emailChanged := user.Email != previousEmail
+	if emailChanged {
+		user.EmailUndeliverable, err = s.storage.IsEmailSuppressed(ctx, user.Email)
+		if err != nil {
+			logg.Error("failed to check suppression list", "error", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+
+		data := map[string]any{"previous_email": previousEmail}
+		outbox = s.webhooks.Deliveries(storage.WebhookUserEmailChanged, user, data)
+	}
+
+	err = s.storage.UpdateUser(ctx, user, outbox...)
+	if err != nil {
+		if errors.Is(err, storage.ErrDuplicateEmail) {
+			logg.Warn("email already exists", "user_id", user.ID)
+			return nil, status.Error(codes.AlreadyExists, "email already exists")
+		}
+		if errors.Is(err, storage.ErrEditConflict) {
+			logg.Warn("edit conflict", "user_id", user.ID)
+			return nil, status.Error(codes.Aborted, "edit conflict")
+		}
+		logg.Error("failed to update user", "user_id", user.ID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if emailChanged {
+		s.audit.Record(ctx, storage.AuditEvent{
+			Event:        storage.AuditEmailChanged,
+			ActorID:      &user.ID,
+			TargetUserID: &user.ID,
+			Outcome:      storage.AuditSuccess,
+			Details:      map[string]any{"previous_email": previousEmail, "email": user.Email},
+		})
+	}
+
+	return userToUserMessage(user), nil
+}
+
+func (s *Server) VerifyToken(ctx context.Context, request *pbuser.VerifyTokenRequest) (*pbuser.UserMessage, error) {
+	logg := logging.FromContext(ctx).With("handler", "verify token")
+
+	user, err := s.userForAuthToken(ctx, logg, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return userToUserMessage(user), nil
+}
+
+func (s *Server) Authorize(ctx context.Context, request *pbuser.AuthorizeRequest) (*pbuser.AuthorizeResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "authorize")
+
+	if len(request.Permissions) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "permissions must not be empty")
+	}
+
+	user, err := s.userForAuthToken(ctx, logg, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, code := range request.Permissions {
+		if !user.Permissions.Include(code) {
+			missing = append(missing, code)
+		}
+	}
+
+	var allowed bool
+	switch request.Match {
+	case pbuser.PermissionMatch_PERMISSION_MATCH_UNSPECIFIED, pbuser.PermissionMatch_PERMISSION_MATCH_ALL:
+		allowed = len(missing) == 0
+	case pbuser.PermissionMatch_PERMISSION_MATCH_ANY:
+		allowed = len(missing) < len(request.Permissions)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown permission match")
+	}
+	if allowed {
+		missing = nil
+	}
+
+	return &pbuser.AuthorizeResponse{
+		Allowed: allowed,
+		UserId:  user.ID,
+		Missing: missing,
+	}, nil
+}
+
+// userForAuthToken resolves an authentication token to its user together
+// with the user's permissions. An empty token resolves to AnonymousUser.
+func (s *Server) userForAuthToken(ctx context.Context, logg *slog.Logger, token string) (*storage.User, error) {
+	if token == "" {
+		return storage.AnonymousUser, nil
+	}
+
+	if len(token) != 26 {
 		return nil, status.Error(codes.InvalidArgument, "invalid token")
 	}
 
-	user, err := s.storage.GetUserForToken(storage.ScopeAuthentication, request.Token)
+	user, err := s.storage.GetUserForToken(ctx, storage.ScopeAuthentication, token)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.Unauthenticated, "invalid token")
@@ -176,13 +486,76 @@ func (s *Server) VerifyToken(_ context.Context, request *pbuser.VerifyTokenReque
 		logg.Error("failed to get user by token", "error", err)
 		return nil, status.Error(codes.Internal, "internal error")
 	}
-	user.Permissions, err = s.storage.GetAllUserPermissions(user.ID)
+	if user.IsSuspended(time.Now()) {
+		return nil, status.Error(codes.PermissionDenied, "account suspended")
+	}
+	user.Permissions, err = s.storage.GetAllUserPermissions(ctx, user.ID)
 	if err != nil {
 		logg.Error("failed to get user permissions", "error", err)
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	return userToUserMessage(user), nil
+	return user, nil
+}
+
+func (s *Server) VerifyTokens(
+	ctx context.Context,
+	request *pbuser.VerifyTokensRequest,
+) (*pbuser.VerifyTokensResponse, error) {
+	logg := logging.FromContext(ctx).With("handler", "verify tokens")
+
+	if len(request.Tokens) > maxVerifyTokens {
+		return nil, status.Errorf(codes.InvalidArgument, "too many tokens, maximum is %d", maxVerifyTokens)
+	}
+
+	results := make([]*pbuser.VerifyTokenResult, len(request.Tokens))
+	lookup := make([]string, 0, len(request.Tokens))
+	for i, token := range request.Tokens {
+		result := &pbuser.VerifyTokenResult{Token: token}
+		results[i] = result
+
+		switch {
+		case token == "":
+			result.User = userToUserMessage(storage.AnonymousUser)
+		case len(token) != 26:
+			result.Code = int32(codes.InvalidArgument)
+			result.Error = "invalid token"
+		default:
+			lookup = append(lookup, token)
+		}
+	}
+
+	if len(lookup) == 0 {
+		return &pbuser.VerifyTokensResponse{Results: results}, nil
+	}
+
+	users, err := s.storage.GetUsersForTokens(ctx, storage.ScopeAuthentication, lookup)
+	if err != nil {
+		logg.Error("failed to get users for tokens", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	now := time.Now()
+	for _, result := range results {
+		if result.User != nil || result.Code != int32(codes.OK) {
+			continue
+		}
+
+		user, ok := users[result.Token]
+		if !ok {
+			result.Code = int32(codes.Unauthenticated)
+			result.Error = "invalid token"
+			continue
+		}
+		if user.IsSuspended(now) {
+			result.Code = int32(codes.PermissionDenied)
+			result.Error = "account suspended"
+			continue
+		}
+		result.User = userToUserMessage(user)
+	}
+
+	return &pbuser.VerifyTokensResponse{Results: results}, nil
 }
 
 func userToUserMessage(user *storage.User) *pbuser.UserMessage {
@@ -193,5 +566,6 @@ func userToUserMessage(user *storage.User) *pbuser.UserMessage {
 		Activated:   user.Activated,
 		CreatedAt:   user.CreatedAt.Unix(),
 		Permissions: user.Permissions,
+		Locale:      user.Locale,
 	}
 }
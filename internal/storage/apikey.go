@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/rpcmethod"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyUseResolution is how stale last_used_at may get before a call
+// records a new one.
+const APIKeyUseResolution = time.Minute
+
+// APIKey is a credential for another service. Only the SHA-256 hash of the
+// key is stored; the plaintext is shown once, when the key is created.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	CreatedBy  *int64     `json:"created_by"`
+	Name       string     `json:"name" validate:"required,lte=100"`
+	Plaintext  string     `json:"-" db:"-"`
+	Hash       []byte     `json:"-"`
+	Methods    []string   `json:"methods" validate:"required,dive,required"`
+	Expiry     *time.Time `json:"expiry"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+func GenerateAPIKey(name string, methods []string, expiry *time.Time, createdBy int64) (*APIKey, error) {
+	key := &APIKey{
+		Name:      name,
+		Methods:   methods,
+		Expiry:    expiry,
+		CreatedBy: &createdBy,
+	}
+
+	randomBytes := make([]byte, 32)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Plaintext = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes)
+	key.Hash = HashAPIKey(key.Plaintext)
+	return key, nil
+}
+
+func HashAPIKey(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
+
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.Expiry != nil && !k.Expiry.After(now)
+}
+
+// UseIsStale reports whether a call at now should update LastUsedAt.
+func (k *APIKey) UseIsStale(now time.Time) bool {
+	return k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= APIKeyUseResolution
+}
+
+// Allows reports whether the key may call the full gRPC method, listed
+// either as is, by its short name, or through "*".
+func (k *APIKey) Allows(method string) bool {
+	return rpcmethod.MatchesAny(k.Methods, method)
+}
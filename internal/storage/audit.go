@@ -0,0 +1,51 @@
+package storage
+
+import "time"
+
+const (
+	AuditUserRegistered     = "user.registered"
+	AuditUserActivated      = "user.activated"
+	AuditUserLogin          = "user.login"
+	AuditUserSuspended      = "user.suspended"
+	AuditUserUnsuspended    = "user.unsuspended"
+	AuditUserDeleted        = "user.deleted"
+	AuditEmailChanged       = "email.changed"
+	AuditTokensRevoked      = "tokens.revoked"
+	AuditPermissionsGranted = "permissions.granted"
+	AuditPermissionsRevoked = "permissions.revoked"
+	AuditPasswordChanged    = "password.changed"
+	AuditSuppressionDeleted = "suppression.deleted"
+	AuditAPIKeyCreated      = "api_key.created"
+	AuditAPIKeyRevoked      = "api_key.revoked"
+)
+
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+type AuditEvent struct {
+	ID           int64          `json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Event        string         `json:"event"`
+	ActorID      *int64         `json:"actor_id"`
+	TargetUserID *int64         `json:"target_user_id"`
+	PeerIP       string         `json:"peer_ip"`
+	Method       string         `json:"method"`
+	Outcome      string         `json:"outcome"`
+	Details      map[string]any `json:"details"`
+}
+
+type AuditFilter struct {
+	Event         string
+	ActorID       int64
+	TargetUserID  int64
+	Outcome       string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	// BeforeID continues a newest-first listing from the last event of the
+	// previous page.
+	BeforeID int64
+	Limit    int
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrEmailNotFound = errors.New("email not found")
+
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailFailed  = "failed"
+)
+
+type Email struct {
+	ID            int64          `json:"id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Recipient     string         `json:"recipient"`
+	Template      string         `json:"template"`
+	Locale        string         `json:"locale"`
+	Data          map[string]any `json:"-"`
+	Status        string         `json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     string         `json:"last_error"`
+	SentAt        *time.Time     `json:"sent_at"`
+
+	user *User
+}
+
+// NewEmail queues templateFile for user. The user's id is added to data as
+// "userID" when the email is written, after the user itself has been stored.
+func NewEmail(user *User, templateFile string, data map[string]any) *Email {
+	if data == nil {
+		data = map[string]any{}
+	}
+	return &Email{
+		Recipient: user.Email,
+		Template:  templateFile,
+		Locale:    user.Locale,
+		Data:      data,
+		user:      user,
+	}
+}
+
+func (*Email) outboxMessage() {}
+
+func (e *Email) PrepareData() {
+	if e.user != nil {
+		e.Data["userID"] = e.user.ID
+	}
+}
+
+type EmailFilter struct {
+	Status   string
+	BeforeID int64
+	Limit    int
+}
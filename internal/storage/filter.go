@@ -0,0 +1,25 @@
+package storage
+
+import "time"
+
+const (
+	UserSortID        = "id"
+	UserSortCreatedAt = "created_at"
+	UserSortEmail     = "email"
+)
+
+type UserFilter struct {
+	EmailPrefix   string
+	Activated     *bool
+	Suspended     *bool
+	Permission    string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	Sort       string
+	Descending bool
+	// After continues a listing from the position of the last user on the
+	// previous page. Only the fields used by Sort, plus ID, are relevant.
+	After *User
+	Limit int
+}
@@ -0,0 +1,8 @@
+package storage
+
+// OutboxMessage is a side effect of a user change, such as a webhook
+// delivery, a queued email or a token, that is written in the same
+// transaction as the change itself.
+type OutboxMessage interface {
+	outboxMessage()
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s Storage) InsertAuditEvent(ctx context.Context, event *storage.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (event, actor_id, target_user_id, peer_ip, method, outcome, details)
+		VALUES (@event, @actor_id, @target_user_id, @peer_ip, @method, @outcome, @details)
+		RETURNING id, created_at`
+
+	details := event.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	args := pgx.NamedArgs{
+		"event":          event.Event,
+		"actor_id":       event.ActorID,
+		"target_user_id": event.TargetUserID,
+		"peer_ip":        event.PeerIP,
+		"method":         event.Method,
+		"outcome":        event.Outcome,
+		"details":        details,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err := s.db.QueryRow(ctx, query, args).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	return nil
+}
+
+func (s Storage) ListAuditEvents(ctx context.Context, filter storage.AuditFilter) ([]*storage.AuditEvent, error) {
+	var conditions []string
+	args := pgx.NamedArgs{
+		"limit": filter.Limit,
+	}
+
+	if filter.Event != "" {
+		conditions = append(conditions, "event = @event")
+		args["event"] = filter.Event
+	}
+	if filter.ActorID != 0 {
+		conditions = append(conditions, "actor_id = @actor_id")
+		args["actor_id"] = filter.ActorID
+	}
+	if filter.TargetUserID != 0 {
+		conditions = append(conditions, "target_user_id = @target_user_id")
+		args["target_user_id"] = filter.TargetUserID
+	}
+	if filter.Outcome != "" {
+		conditions = append(conditions, "outcome = @outcome")
+		args["outcome"] = filter.Outcome
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= @created_after")
+		args["created_after"] = *filter.CreatedAfter
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < @created_before")
+		args["created_before"] = *filter.CreatedBefore
+	}
+	if filter.BeforeID != 0 {
+		conditions = append(conditions, "id < @before_id")
+		args["before_id"] = filter.BeforeID
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, created_at, event, actor_id, target_user_id, peer_ip, method, outcome, details
+		FROM audit_events
+		%s
+		ORDER BY id DESC
+		LIMIT @limit`, where)
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+
+	events, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[storage.AuditEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect audit events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+func insertEmail(ctx context.Context, tx pgx.Tx, email *storage.Email) error {
+	email.PrepareData()
+
+	query := `
+		INSERT INTO email_outbox (recipient, template, locale, data)
+		VALUES (@recipient, @template, @locale, @data)
+		RETURNING id, created_at, status, next_attempt_at`
+
+	args := pgx.NamedArgs{
+		"recipient": email.Recipient,
+		"template":  email.Template,
+		"locale":    email.Locale,
+		"data":      email.Data,
+	}
+
+	err := tx.QueryRow(ctx, query, args).
+		Scan(&email.ID, &email.CreatedAt, &email.Status, &email.NextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert email: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimEmails picks up to limit due emails and pushes their next attempt past
+// lease, so that other workers and replicas skip them while they are sent.
+func (s Storage) ClaimEmails(ctx context.Context, limit int, lease time.Duration) ([]*storage.Email, error) {
+	query := `
+		UPDATE email_outbox
+		SET next_attempt_at = @lease_until
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = @pending AND next_attempt_at <= @now
+			ORDER BY next_attempt_at, id
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, created_at, recipient, template, locale, data, status, attempts, next_attempt_at, last_error, sent_at`
+
+	now := time.Now()
+	args := pgx.NamedArgs{
+		"pending":     storage.EmailPending,
+		"now":         now,
+		"lease_until": now.Add(lease),
+		"limit":       limit,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim emails: %w", err)
+	}
+
+	emails, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[storage.Email])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect emails: %w", err)
+	}
+
+	return emails, nil
+}
+
+// MarkEmailSent also clears the template data, which may hold one-time
+// tokens that are no longer needed once the email is out.
+func (s Storage) MarkEmailSent(ctx context.Context, id int64) error {
+	query := `
+		UPDATE email_outbox
+		SET status = @status, attempts = attempts + 1, last_error = '', data = '{}', sent_at = NOW()
+		WHERE id = @id`
+
+	args := pgx.NamedArgs{
+		"status": storage.EmailSent,
+		"id":     id,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to mark email sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkEmailFailed records a failed attempt. The email is retried at
+// nextAttempt unless failed is set, which parks it until an operator retries.
+// The template data is kept so that a retried email still renders.
+func (s Storage) MarkEmailFailed(
+	ctx context.Context,
+	id int64,
+	lastError string,
+	nextAttempt time.Time,
+	failed bool,
+) error {
+	query := `
+		UPDATE email_outbox
+		SET status = @status, attempts = attempts + 1, last_error = @last_error, next_attempt_at = @next_attempt_at
+		WHERE id = @id`
+
+	status := storage.EmailPending
+	if failed {
+		status = storage.EmailFailed
+	}
+
+	args := pgx.NamedArgs{
+		"status":          status,
+		"last_error":      lastError,
+		"next_attempt_at": nextAttempt,
+		"id":              id,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to mark email failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s Storage) ListEmails(ctx context.Context, filter storage.EmailFilter) ([]*storage.Email, error) {
+	var conditions []string
+	args := pgx.NamedArgs{
+		"limit": filter.Limit,
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = @status")
+		args["status"] = filter.Status
+	}
+	if filter.BeforeID != 0 {
+		conditions = append(conditions, "id < @before_id")
+		args["before_id"] = filter.BeforeID
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, created_at, recipient, template, locale, data, status, attempts, next_attempt_at, last_error, sent_at
+		FROM email_outbox
+		%s
+		ORDER BY id DESC
+		LIMIT @limit`, where)
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query emails: %w", err)
+	}
+
+	emails, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[storage.Email])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect emails: %w", err)
+	}
+
+	return emails, nil
+}
+
+// RetryEmail puts a failed email back in the queue with a fresh attempt budget.
+// It is sent with the data it was queued with, so an activation link in it
+// may have expired by then.
+func (s Storage) RetryEmail(ctx context.Context, id int64) (*storage.Email, error) {
+	query := `
+		UPDATE email_outbox
+		SET status = @pending, attempts = 0, next_attempt_at = NOW()
+		WHERE id = @id AND status = @failed
+		RETURNING id, created_at, recipient, template, locale, data, status, attempts, next_attempt_at, last_error, sent_at`
+
+	args := pgx.NamedArgs{
+		"pending": storage.EmailPending,
+		"failed":  storage.EmailFailed,
+		"id":      id,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry email: %w", err)
+	}
+
+	email, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[storage.Email])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrEmailNotFound
+		}
+		return nil, fmt.Errorf("failed to collect retried email: %w", err)
+	}
+
+	return email, nil
+}
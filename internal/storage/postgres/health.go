@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// SchemaVersion is the goose version of the newest migration in migrations/.
+// Bump it together with every new migration; a test keeps the two in sync.
+const SchemaVersion = 14
+
+func (s Storage) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+// CheckSchema fails until the migrations are applied up to SchemaVersion.
+func (s Storage) CheckSchema(ctx context.Context) error {
+	query := `
+		SELECT version_id FROM goose_db_version
+		WHERE is_applied
+		ORDER BY id DESC
+		LIMIT 1`
+
+	var version int64
+	err := s.db.QueryRow(ctx, query).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("schema version is %d, expected %d", version, SchemaVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSchemaVersionMatchesMigrations(t *testing.T) {
+	entries, err := os.ReadDir("../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to read migrations: %v", err)
+	}
+
+	var newest int64
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			t.Fatalf("migration %s has no numeric version: %v", entry.Name(), err)
+		}
+		newest = max(newest, version)
+	}
+
+	if newest != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, newest migration is %d", SchemaVersion, newest)
+	}
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// insertOutbox writes messages belonging to userID within tx. Tokens created
+// before the user had an id are assigned to it here.
+func insertOutbox(ctx context.Context, tx pgx.Tx, userID int64, messages []storage.OutboxMessage) error {
+	for _, message := range messages {
+		var err error
+		switch m := message.(type) {
+		case *storage.WebhookDelivery:
+			err = insertWebhookDelivery(ctx, tx, m)
+		case *storage.Email:
+			err = insertEmail(ctx, tx, m)
+		case *storage.Token:
+			if m.UserID == 0 {
+				m.UserID = userID
+			}
+			err = insertToken(ctx, tx, m)
+			if err != nil {
+				err = fmt.Errorf("failed to insert token: %w", err)
+			}
+		default:
+			err = fmt.Errorf("unknown outbox message %T", message)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordOutbox counts the tokens among messages once the transaction that
+// inserted them has committed.
+func recordOutbox(messages []storage.OutboxMessage) {
+	for _, message := range messages {
+		if token, ok := message.(*storage.Token); ok {
+			metrics.TokenIssued(token.Scope)
+		}
+	}
+}
+
+// OutboxBacklog returns the number of pending messages per outbox, keyed
+// "emails" and "webhooks".
+func (s Storage) OutboxBacklog(ctx context.Context) (map[string]int64, error) {
+	query := `
+		SELECT 'emails', count(*) FROM email_outbox WHERE status = @email_pending
+		UNION ALL
+		SELECT 'webhooks', count(*) FROM webhook_outbox WHERE status = @webhook_pending`
+
+	args := pgx.NamedArgs{
+		"email_pending":   storage.EmailPending,
+		"webhook_pending": storage.WebhookPending,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox backlog: %w", err)
+	}
+
+	backlog := make(map[string]int64, 2)
+	var (
+		outbox string
+		count  int64
+	)
+	_, err = pgx.ForEachRow(rows, []any{&outbox, &count}, func() error {
+		backlog[outbox] = count
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect outbox backlog: %w", err)
+	}
+
+	return backlog, nil
+}
@@ -3,33 +3,42 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
 
+	"github.com/AndreyChufelin/movies-auth/internal/config"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Storage struct {
-	db       *pgxpool.Pool
-	host     string
-	port     string
-	user     string
-	password string
-	name     string
+	db   *pgxpool.Pool
+	conf config.DBConf
 }
 
-func NewStorage(host, port, user, password, name string) Storage {
-	return Storage{
-		host:     host,
-		port:     port,
-		user:     user,
-		password: password,
-		name:     name,
-	}
+func NewStorage(conf config.DBConf) Storage {
+	return Storage{conf: conf}
 }
 
 func (s *Storage) Connect(ctx context.Context) error {
-	var err error
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", s.user, s.password, s.host, s.port, s.name)
-	s.db, err = pgxpool.New(ctx, dsn)
+	poolConfig, err := pgxpool.ParseConfig(s.dsn())
+	if err != nil {
+		return fmt.Errorf("failed to parse postgres dsn: %w", err)
+	}
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	// pgxpool has no cap on idle connections, so MaxIdleConns becomes the
+	// number of connections kept open; the rest close after MaxIdleTime.
+	if s.conf.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(s.conf.MaxOpenConns)
+	}
+	if s.conf.MaxIdleConns > 0 {
+		poolConfig.MinConns = int32(min(s.conf.MaxIdleConns, int(poolConfig.MaxConns)))
+	}
+	if s.conf.MaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = s.conf.MaxIdleTime
+	}
+
+	s.db, err = pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to postgres: %w", err)
 	}
@@ -37,6 +46,42 @@ func (s *Storage) Connect(ctx context.Context) error {
 	return nil
 }
 
+// dsn returns the configured DSN, or builds one from the individual
+// settings with every part escaped.
+func (s *Storage) dsn() string {
+	if s.conf.DSN != "" {
+		return s.conf.DSN
+	}
+
+	query := url.Values{}
+	if s.conf.SSLMode != "" {
+		query.Set("sslmode", s.conf.SSLMode)
+	}
+	if s.conf.SSLRootCert != "" {
+		query.Set("sslrootcert", s.conf.SSLRootCert)
+	}
+	if s.conf.TargetSessionAttrs != "" {
+		query.Set("target_session_attrs", s.conf.TargetSessionAttrs)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(s.conf.User, s.conf.Password),
+		Host:     net.JoinHostPort(s.conf.Host, s.conf.Port),
+		Path:     "/" + s.conf.Name,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+// PoolStat returns connection pool statistics, or nil before Connect.
+func (s *Storage) PoolStat() *pgxpool.Stat {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Stat()
+}
+
 func (s *Storage) Close(_ context.Context) error {
 	if s.db == nil {
 		return fmt.Errorf("no connection to close")
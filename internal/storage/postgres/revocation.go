@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+)
+
+const revocationsChannel = "revocations"
+
+// ListenRevocations holds a dedicated connection listening on the revocations
+// channel and passes every notification to handle until ctx is done or the
+// connection fails. Notifications sent while not listening are lost, so once
+// listening handle first gets a resync event.
+func (s Storage) ListenRevocations(ctx context.Context, handle func(storage.RevocationEvent)) error {
+	poolConn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+revocationsChannel)
+	if err != nil {
+		return fmt.Errorf("failed to listen for revocations: %w", err)
+	}
+	handle(storage.RevocationEvent{Type: storage.RevocationResync})
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+
+		var event storage.RevocationEvent
+		err = json.Unmarshal([]byte(notification.Payload), &event)
+		if err != nil {
+			return fmt.Errorf("failed to decode revocation event: %w", err)
+		}
+
+		handle(event)
+	}
+}
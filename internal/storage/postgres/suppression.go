@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// AddSuppressions stores the addresses and flags the accounts using them as
+// undeliverable. Re-reporting an address keeps the original entry.
+func (s Storage) AddSuppressions(ctx context.Context, suppressions []*storage.Suppression) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, suppression := range suppressions {
+			batch.Queue(`
+				INSERT INTO email_suppressions (email, reason, detail)
+				VALUES (@email, @reason, @detail)
+				ON CONFLICT (email) DO NOTHING`,
+				pgx.NamedArgs{
+					"email":  suppression.Email,
+					"reason": suppression.Reason,
+					"detail": suppression.Detail,
+				})
+			batch.Queue(`
+				UPDATE users
+				SET email_undeliverable = true, version = version + 1
+				WHERE email = $1 AND NOT email_undeliverable`,
+				suppression.Email)
+		}
+
+		err := tx.SendBatch(ctx, batch).Close()
+		if err != nil {
+			return fmt.Errorf("failed to add suppressions: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s Storage) IsEmailSuppressed(ctx context.Context, email string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM email_suppressions WHERE email = $1)`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var suppressed bool
+	err := s.db.QueryRow(ctx, query, email).Scan(&suppressed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check suppression: %w", err)
+	}
+
+	return suppressed, nil
+}
+
+func (s Storage) ListSuppressions(
+	ctx context.Context,
+	filter storage.SuppressionFilter,
+) ([]*storage.Suppression, error) {
+	var conditions []string
+	args := pgx.NamedArgs{
+		"limit": filter.Limit,
+	}
+
+	if filter.EmailPrefix != "" {
+		conditions = append(conditions, `email LIKE @email_prefix || '%'`)
+		args["email_prefix"] = escapeLike(filter.EmailPrefix)
+	}
+	if filter.AfterEmail != "" {
+		conditions = append(conditions, "email > @after_email")
+		args["after_email"] = filter.AfterEmail
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT email, created_at, reason, detail
+		FROM email_suppressions
+		%s
+		ORDER BY email
+		LIMIT @limit`, where)
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suppressions: %w", err)
+	}
+
+	suppressions, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[storage.Suppression])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect suppressions: %w", err)
+	}
+
+	return suppressions, nil
+}
+
+// DeleteSuppression removes the address from the list and clears the
+// undeliverable flag of the account using it.
+func (s Storage) DeleteSuppression(ctx context.Context, email string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM email_suppressions WHERE email = $1`, email)
+		if err != nil {
+			return fmt.Errorf("failed to delete suppression: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return storage.ErrSuppressionNotFound
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE users
+			SET email_undeliverable = false, version = version + 1
+			WHERE email = $1 AND email_undeliverable`,
+			email)
+		if err != nil {
+			return fmt.Errorf("failed to clear undeliverable flag: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -4,20 +4,34 @@ import (
 	"context"
 	"time"
 
+	"github.com/AndreyChufelin/movies-auth/internal/metrics"
 	"github.com/AndreyChufelin/movies-auth/internal/storage"
 	"github.com/jackc/pgx/v5"
 )
 
-func (s Storage) NewToken(userID int64, ttl time.Duration, scope string) (*storage.Token, error) {
+func (s Storage) NewToken(ctx context.Context, userID int64, ttl time.Duration, scope string) (*storage.Token, error) {
 	token, err := storage.GenerateToken(userID, ttl, scope)
 	if err != nil {
 		return nil, err
 	}
-	err = s.InsertToken(token)
+	err = s.InsertToken(ctx, token)
 	return token, err
 }
 
-func (s Storage) InsertToken(token *storage.Token) error {
+func (s Storage) InsertToken(ctx context.Context, token *storage.Token) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err := insertToken(ctx, s.db, token)
+	if err != nil {
+		return err
+	}
+
+	metrics.TokenIssued(token.Scope)
+	return nil
+}
+
+func insertToken(ctx context.Context, db execer, token *storage.Token) error {
 	query := `
 		INSERT INTO tokens (hash, user_id, expiry, scope)
 		VALUES (@hash, @user_id, @expiry, @scope)`
@@ -29,15 +43,12 @@ func (s Storage) InsertToken(token *storage.Token) error {
 		"scope":   token.Scope,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	_, err := s.db.Exec(ctx, query, args)
-
+	_, err := db.Exec(ctx, query, args)
 	return err
 }
 
-func (s Storage) DeleteToAllTokensForUser(scope string, userID int64) error {
+// DeleteToAllTokensForUser returns how many tokens of scope were deleted.
+func (s Storage) DeleteToAllTokensForUser(ctx context.Context, scope string, userID int64) (int, error) {
 	query := `
 		DELETE FROM tokens
 		WHERE scope = @scope AND user_id = @user_id`
@@ -47,10 +58,45 @@ func (s Storage) DeleteToAllTokensForUser(scope string, userID int64) error {
 		"scope":   scope,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	_, err := s.db.Exec(ctx, query, args)
+	tag, err := s.db.Exec(ctx, query, args)
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	return int(tag.RowsAffected()), nil
+}
+
+// DeleteAllTokensForUser returns how many tokens were deleted, by scope.
+func (s Storage) DeleteAllTokensForUser(ctx context.Context, userID int64) (map[string]int, error) {
+	query := `
+		DELETE FROM tokens
+		WHERE user_id = @user_id
+		RETURNING scope`
+
+	args := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[string]int)
+	for _, scope := range scopes {
+		deleted[scope]++
+	}
+
+	return deleted, nil
 }
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var recorder = tracetest.NewSpanRecorder()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+}
+
+// endedSince returns the spans that ended after the first n.
+func endedSince(n int) []sdktrace.ReadOnlySpan {
+	return recorder.Ended()[n:]
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestQueryTracer(t *testing.T) {
+	tests := []struct {
+		name      string
+		traced    bool
+		sql       string
+		err       error
+		spanName  string
+		operation string
+	}{
+		{name: "untraced query", sql: "SELECT 1"},
+		{
+			name:      "traced query",
+			traced:    true,
+			sql:       "\n\t\tselect id FROM users",
+			spanName:  "postgres SELECT",
+			operation: "SELECT",
+		},
+		{
+			name:      "failed query",
+			traced:    true,
+			sql:       "UPDATE users SET name = $1",
+			err:       errors.New("deadlock detected"),
+			spanName:  "postgres UPDATE",
+			operation: "UPDATE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var parent trace.Span
+			if tt.traced {
+				ctx, parent = otel.Tracer("test").Start(ctx, "parent")
+				defer parent.End()
+			}
+
+			n := len(recorder.Ended())
+			qt := queryTracer{}
+			ctx = qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: tt.sql})
+			qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{
+				CommandTag: pgconn.NewCommandTag("UPDATE 3"),
+				Err:        tt.err,
+			})
+
+			spans := endedSince(n)
+			if !tt.traced {
+				if len(spans) != 0 {
+					t.Fatalf("recorded %d spans without a parent", len(spans))
+				}
+				return
+			}
+			if len(spans) != 1 {
+				t.Fatalf("recorded %d spans, want 1", len(spans))
+			}
+
+			span := spans[0]
+			if span.Name() != tt.spanName {
+				t.Errorf("name = %q, want %q", span.Name(), tt.spanName)
+			}
+			if span.SpanKind() != trace.SpanKindClient {
+				t.Errorf("kind = %v, want client", span.SpanKind())
+			}
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("parent = %v, want %v", span.Parent().SpanID(), parent.SpanContext().SpanID())
+			}
+			if v, _ := attributeValue(span, "db.operation.name"); v.AsString() != tt.operation {
+				t.Errorf("db.operation.name = %q, want %q", v.AsString(), tt.operation)
+			}
+			if v, _ := attributeValue(span, "db.query.text"); v.AsString() != tt.sql {
+				t.Errorf("db.query.text = %q, want %q", v.AsString(), tt.sql)
+			}
+			if v, _ := attributeValue(span, "db.rows_affected"); v.AsInt64() != 3 {
+				t.Errorf("db.rows_affected = %d, want 3", v.AsInt64())
+			}
+
+			wantStatus := codes.Unset
+			if tt.err != nil {
+				wantStatus = codes.Error
+			}
+			if span.Status().Code != wantStatus {
+				t.Errorf("status = %v, want %v", span.Status().Code, wantStatus)
+			}
+		})
+	}
+}
+
+func TestQueryTracerBatch(t *testing.T) {
+	batch := &pgx.Batch{}
+	batch.Queue("INSERT INTO email_suppressions (email) VALUES ($1)", "a@example.com")
+	batch.Queue("UPDATE users SET email_undeliverable = true WHERE email = $1", "a@example.com")
+
+	qt := queryTracer{}
+	run := func(ctx context.Context) {
+		ctx = qt.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{Batch: batch})
+		for _, query := range batch.QueuedQueries {
+			qt.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: query.SQL})
+		}
+		qt.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+	}
+
+	n := len(recorder.Ended())
+	run(context.Background())
+	if spans := endedSince(n); len(spans) != 0 {
+		t.Fatalf("recorded %d spans without a parent", len(spans))
+	}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+	run(ctx)
+
+	spans := endedSince(n)
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "postgres batch" {
+		t.Errorf("name = %q", span.Name())
+	}
+	if v, _ := attributeValue(span, "db.batch.size"); v.AsInt64() != 2 {
+		t.Errorf("db.batch.size = %d, want 2", v.AsInt64())
+	}
+	if len(span.Events()) != 2 {
+		t.Errorf("recorded %d query events, want 2", len(span.Events()))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AndreyChufelin/movies-auth/internal/storage"
@@ -13,42 +14,53 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-func (s Storage) InsertUser(user *storage.User) error {
+func (s Storage) InsertUser(ctx context.Context, user *storage.User, outbox ...storage.OutboxMessage) error {
 	query := `
-		INSERT INTO users (name, email, password_hash, activated)
-		VALUES (@name, @email, @password, @activated)
+		INSERT INTO users (name, email, password_hash, activated, locale, email_undeliverable)
+		VALUES (@name, @email, @password, @activated, @locale, @email_undeliverable)
 		RETURNING id, created_at, version`
 
 	args := pgx.NamedArgs{
-		"name":      user.Name,
-		"email":     user.Email,
-		"password":  user.PasswordHash,
-		"activated": user.Activated,
+		"name":                user.Name,
+		"email":               user.Email,
+		"password":            user.PasswordHash,
+		"activated":           user.Activated,
+		"locale":              user.Locale,
+		"email_undeliverable": user.EmailUndeliverable,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	err := s.db.QueryRow(ctx, query, args).
-		Scan(&user.ID, &user.CreatedAt, &user.Version)
-	if err != nil {
-		var e *pgconn.PgError
-		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			return storage.ErrDuplicateEmail
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, args).
+			Scan(&user.ID, &user.CreatedAt, &user.Version)
+		if err != nil {
+			var e *pgconn.PgError
+			if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+				return storage.ErrDuplicateEmail
+			}
+			return fmt.Errorf("failed to insert user: %w", err)
 		}
-		return fmt.Errorf("failed to insert user: %w", err)
+
+		return insertOutbox(ctx, tx, user.ID, outbox)
+	})
+	if err != nil {
+		return err
 	}
 
+	recordOutbox(outbox)
 	return nil
 }
 
-func (s Storage) GetUserByEmail(email string) (*storage.User, error) {
+func (s Storage) GetUserByEmail(ctx context.Context, email string) (*storage.User, error) {
 	query := `
-		SELECT id, created_at, name, email, password_hash, activated, version
+		SELECT id, created_at, name, email, password_hash, activated, version,
+			suspended_at, suspended_until, suspended_reason, suspended_by, locale, email_undeliverable
 		FROM users
 		WHERE email = $1`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	row, err := s.db.Query(ctx, query, email)
@@ -67,45 +79,121 @@ func (s Storage) GetUserByEmail(email string) (*storage.User, error) {
 	return &user, nil
 }
 
-func (s Storage) UpdateUser(user *storage.User) error {
+func (s Storage) GetUserByID(ctx context.Context, id int64) (*storage.User, error) {
+	query := `
+		SELECT id, created_at, name, email, password_hash, activated, version,
+			suspended_at, suspended_until, suspended_reason, suspended_by, locale, email_undeliverable
+		FROM users
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	row, err := s.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user by id: %w", err)
+	}
+
+	user, err := pgx.CollectOneRow(row, pgx.RowToStructByName[storage.User])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to collect user by id: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (s Storage) UpdateUser(ctx context.Context, user *storage.User, outbox ...storage.OutboxMessage) error {
 	query := `
 		UPDATE users
-		SET name = @name, email = @email, password_hash = @password, activated = @activated, version = version + 1
+		SET name = @name, email = @email, password_hash = @password, activated = @activated,
+			suspended_at = @suspended_at, suspended_until = @suspended_until,
+			suspended_reason = @suspended_reason, suspended_by = @suspended_by,
+			locale = @locale, email_undeliverable = @email_undeliverable, version = version + 1
 		WHERE id = @id AND version = @version
 		RETURNING version`
 
 	args := pgx.NamedArgs{
-		"name":      user.Name,
-		"email":     user.Email,
-		"password":  user.PasswordHash,
-		"activated": user.Activated,
-		"id":        user.ID,
-		"version":   user.Version,
+		"name":                user.Name,
+		"email":               user.Email,
+		"password":            user.PasswordHash,
+		"activated":           user.Activated,
+		"suspended_at":        user.SuspendedAt,
+		"suspended_until":     user.SuspendedUntil,
+		"suspended_reason":    user.SuspendedReason,
+		"suspended_by":        user.SuspendedBy,
+		"locale":              user.Locale,
+		"email_undeliverable": user.EmailUndeliverable,
+		"id":                  user.ID,
+		"version":             user.Version,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	err := s.db.QueryRow(ctx, query, args).Scan(&user.Version)
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, args).Scan(&user.Version)
+		if err != nil {
+			var e *pgconn.PgError
+			if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+				return storage.ErrDuplicateEmail
+			}
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storage.ErrEditConflict
+			}
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		return insertOutbox(ctx, tx, user.ID, outbox)
+	})
 	if err != nil {
-		var e *pgconn.PgError
-		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			return storage.ErrDuplicateEmail
+		return err
+	}
+
+	recordOutbox(outbox)
+	return nil
+}
+
+func (s Storage) DeleteUser(ctx context.Context, id int64, outbox ...storage.OutboxMessage) error {
+	query := `
+		DELETE FROM users
+		WHERE id = @id`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, query, args)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
 		}
-		if errors.Is(err, pgx.ErrNoRows) {
-			return storage.ErrEditConflict
+		if result.RowsAffected() == 0 {
+			return storage.ErrUserNotFound
 		}
-		return fmt.Errorf("failed to update user: %w", err)
+
+		return insertOutbox(ctx, tx, id, outbox)
+	})
+	if err != nil {
+		return err
 	}
 
+	recordOutbox(outbox)
 	return nil
 }
 
-func (s Storage) GetUserForToken(scope, tokenPlaintext string) (*storage.User, error) {
+func (s Storage) GetUserForToken(ctx context.Context, scope, tokenPlaintext string) (*storage.User, error) {
 	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
 
 	query := `
-		SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.version
+		SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.version,
+			users.suspended_at, users.suspended_until, users.suspended_reason, users.suspended_by, users.locale,
+			users.email_undeliverable
 		FROM users
 		INNER JOIN tokens
 		ON users.id = tokens.user_id
@@ -121,7 +209,7 @@ func (s Storage) GetUserForToken(scope, tokenPlaintext string) (*storage.User, e
 
 	var user storage.User
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	rows, err := s.db.Query(ctx, query, args)
@@ -139,7 +227,7 @@ func (s Storage) GetUserForToken(scope, tokenPlaintext string) (*storage.User, e
 	return &user, nil
 }
 
-func (s Storage) GetAllUserPermissions(userID int64) (storage.Permissions, error) {
+func (s Storage) GetAllUserPermissions(ctx context.Context, userID int64) (storage.Permissions, error) {
 	query := `
 		SELECT permissions.code AS Permissions
 		FROM permissions
@@ -151,7 +239,7 @@ func (s Storage) GetAllUserPermissions(userID int64) (storage.Permissions, error
 		"user_id": userID,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	rows, err := s.db.Query(ctx, query, args)
@@ -171,23 +259,271 @@ func (s Storage) GetAllUserPermissions(userID int64) (storage.Permissions, error
 	return permissions, nil
 }
 
-func (s Storage) AddPermission(userID int64, codes ...string) error {
+// checkPermissions returns a *storage.UnknownPermissionsError naming every
+// code missing from the permissions table.
+func (s Storage) checkPermissions(ctx context.Context, codes []string) error {
+	query := `
+		SELECT requested.code
+		FROM unnest(@codes::text[]) AS requested(code)
+		WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE permissions.code = requested.code)`
+
+	args := pgx.NamedArgs{
+		"codes": codes,
+	}
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to query unknown permissions: %w", err)
+	}
+
+	unknown, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to collect unknown permissions: %w", err)
+	}
+	if len(unknown) > 0 {
+		return &storage.UnknownPermissionsError{Codes: unknown}
+	}
+
+	return nil
+}
+
+func (s Storage) AddPermission(ctx context.Context, userID int64, codes ...string) error {
 	query := `
 		INSERT INTO users_permissions
-		SELECT @user_id, permissions.id FROM permissions WHERE permissions.code = ANY(@codes)`
+		SELECT @user_id, permissions.id FROM permissions WHERE permissions.code = ANY(@codes)
+		ON CONFLICT DO NOTHING`
 
 	args := pgx.NamedArgs{
 		"user_id": userID,
 		"codes":   codes,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	_, err := s.db.Exec(ctx, query, args)
+	err := s.checkPermissions(ctx, codes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, query, args)
 	if err != nil {
 		return fmt.Errorf("failed to add permissions to user: %w", err)
 	}
 
 	return nil
 }
+
+func (s Storage) GetUsersForTokens(
+	ctx context.Context,
+	scope string,
+	tokensPlaintext []string,
+) (map[string]*storage.User, error) {
+	hashes := make([][]byte, 0, len(tokensPlaintext))
+	plaintexts := make(map[string]string, len(tokensPlaintext))
+	for _, t := range tokensPlaintext {
+		hash := sha256.Sum256([]byte(t))
+		hashes = append(hashes, hash[:])
+		plaintexts[string(hash[:])] = t
+	}
+
+	query := `
+		SELECT tokens.hash, users.id, users.created_at, users.name, users.email, users.password_hash,
+			users.activated, users.version,
+			users.suspended_at, users.suspended_until, users.suspended_reason, users.suspended_by, users.locale,
+			users.email_undeliverable,
+			COALESCE(array_agg(permissions.code) FILTER (WHERE permissions.code IS NOT NULL), '{}')
+		FROM tokens
+		INNER JOIN users ON users.id = tokens.user_id
+		LEFT JOIN users_permissions ON users_permissions.user_id = users.id
+		LEFT JOIN permissions ON permissions.id = users_permissions.permission_id
+		WHERE tokens.hash = ANY(@hashes)
+		AND tokens.scope = @scope
+		AND tokens.expiry > @expiry
+		GROUP BY tokens.hash, users.id`
+
+	args := pgx.NamedArgs{
+		"hashes": hashes,
+		"scope":  scope,
+		"expiry": time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users for tokens: %w", err)
+	}
+	defer rows.Close()
+
+	users := make(map[string]*storage.User, len(tokensPlaintext))
+	for rows.Next() {
+		var hash []byte
+		var user storage.User
+		err = rows.Scan(&hash, &user.ID, &user.CreatedAt, &user.Name, &user.Email, &user.PasswordHash,
+			&user.Activated, &user.Version,
+			&user.SuspendedAt, &user.SuspendedUntil, &user.SuspendedReason, &user.SuspendedBy, &user.Locale,
+			&user.EmailUndeliverable,
+			&user.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user for token: %w", err)
+		}
+		users[plaintexts[string(hash)]] = &user
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect users for tokens: %w", err)
+	}
+
+	return users, nil
+}
+
+func (s Storage) ListUsers(ctx context.Context, filter storage.UserFilter) ([]*storage.User, error) {
+	var conditions []string
+	args := pgx.NamedArgs{
+		"now":   time.Now(),
+		"limit": filter.Limit,
+	}
+
+	if filter.EmailPrefix != "" {
+		conditions = append(conditions, `email LIKE @email_prefix || '%'`)
+		args["email_prefix"] = escapeLike(filter.EmailPrefix)
+	}
+	if filter.Activated != nil {
+		conditions = append(conditions, "activated = @activated")
+		args["activated"] = *filter.Activated
+	}
+	if filter.Suspended != nil {
+		suspended := "(suspended_at IS NOT NULL AND (suspended_until IS NULL OR suspended_until > @now))"
+		if !*filter.Suspended {
+			suspended = "NOT " + suspended
+		}
+		conditions = append(conditions, suspended)
+	}
+	if filter.Permission != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM users_permissions
+			INNER JOIN permissions ON permissions.id = users_permissions.permission_id
+			WHERE users_permissions.user_id = users.id AND permissions.code = @permission)`)
+		args["permission"] = filter.Permission
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= @created_after")
+		args["created_after"] = *filter.CreatedAfter
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < @created_before")
+		args["created_before"] = *filter.CreatedBefore
+	}
+
+	sortColumn := "id"
+	switch filter.Sort {
+	case storage.UserSortCreatedAt:
+		sortColumn = "created_at"
+	case storage.UserSortEmail:
+		sortColumn = "email"
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		args["after_id"] = filter.After.ID
+		switch sortColumn {
+		case "created_at":
+			conditions = append(conditions, "(created_at, id) "+comparison+" (@after_value, @after_id)")
+			args["after_value"] = filter.After.CreatedAt
+		case "email":
+			conditions = append(conditions, "(email, id) "+comparison+" (@after_value, @after_id)")
+			args["after_value"] = filter.After.Email
+		default:
+			conditions = append(conditions, "id "+comparison+" @after_id")
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	orderBy := fmt.Sprintf("%s %s", sortColumn, direction)
+	if sortColumn != "id" {
+		orderBy += fmt.Sprintf(", id %s", direction)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, created_at, name, email, password_hash, activated, version,
+			suspended_at, suspended_until, suspended_reason, suspended_by, locale, email_undeliverable,
+			ARRAY(
+				SELECT permissions.code FROM permissions
+				INNER JOIN users_permissions ON users_permissions.permission_id = permissions.id
+				WHERE users_permissions.user_id = users.id
+				ORDER BY permissions.code)
+		FROM users
+		%s
+		ORDER BY %s
+		LIMIT @limit`, where, orderBy)
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*storage.User
+	for rows.Next() {
+		var user storage.User
+		err = rows.Scan(&user.ID, &user.CreatedAt, &user.Name, &user.Email, &user.PasswordHash,
+			&user.Activated, &user.Version,
+			&user.SuspendedAt, &user.SuspendedUntil, &user.SuspendedReason, &user.SuspendedBy, &user.Locale,
+			&user.EmailUndeliverable,
+			&user.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect users: %w", err)
+	}
+
+	return users, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (s Storage) RemovePermission(ctx context.Context, userID int64, codes ...string) error {
+	query := `
+		DELETE FROM users_permissions
+		USING permissions
+		WHERE users_permissions.permission_id = permissions.id
+		AND users_permissions.user_id = @user_id
+		AND permissions.code = ANY(@codes)`
+
+	args := pgx.NamedArgs{
+		"user_id": userID,
+		"codes":   codes,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err := s.checkPermissions(ctx, codes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to remove permissions from user: %w", err)
+	}
+
+	return nil
+}
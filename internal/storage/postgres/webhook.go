@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+func insertWebhookDelivery(ctx context.Context, tx pgx.Tx, delivery *storage.WebhookDelivery) error {
+	err := delivery.EncodePayload()
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_outbox (subscription, event, payload)
+		VALUES (@subscription, @event, @payload)
+		RETURNING id, created_at, status, next_attempt_at`
+
+	args := pgx.NamedArgs{
+		"subscription": delivery.Subscription,
+		"event":        delivery.Event,
+		"payload":      string(delivery.Payload),
+	}
+
+	err = tx.QueryRow(ctx, query, args).
+		Scan(&delivery.ID, &delivery.CreatedAt, &delivery.Status, &delivery.NextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries picks up to limit due deliveries and pushes their
+// next attempt past lease, so that other replicas skip them while they are
+// being sent.
+func (s Storage) ClaimWebhookDeliveries(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*storage.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_outbox
+		SET next_attempt_at = @lease_until
+		WHERE id IN (
+			SELECT id FROM webhook_outbox
+			WHERE status = @pending AND next_attempt_at <= @now
+			ORDER BY next_attempt_at, id
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, created_at, subscription, event, payload, status, attempts, next_attempt_at, last_error`
+
+	now := time.Now()
+	args := pgx.NamedArgs{
+		"pending":     storage.WebhookPending,
+		"now":         now,
+		"lease_until": now.Add(lease),
+		"limit":       limit,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[storage.WebhookDelivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (s Storage) MarkWebhookDelivered(ctx context.Context, id int64) error {
+	query := `
+		UPDATE webhook_outbox
+		SET status = @status, attempts = attempts + 1, last_error = '', delivered_at = NOW()
+		WHERE id = @id`
+
+	args := pgx.NamedArgs{
+		"status": storage.WebhookDelivered,
+		"id":     id,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkWebhookFailed records a failed attempt. The delivery is retried at
+// nextAttempt unless dead is set, which moves it to the dead-letter state.
+func (s Storage) MarkWebhookFailed(
+	ctx context.Context,
+	id int64,
+	lastError string,
+	nextAttempt time.Time,
+	dead bool,
+) error {
+	query := `
+		UPDATE webhook_outbox
+		SET status = @status, attempts = attempts + 1, last_error = @last_error, next_attempt_at = @next_attempt_at
+		WHERE id = @id`
+
+	status := storage.WebhookPending
+	if dead {
+		status = storage.WebhookDead
+	}
+
+	args := pgx.NamedArgs{
+		"status":          status,
+		"last_error":      lastError,
+		"next_attempt_at": nextAttempt,
+		"id":              id,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package storage
+
+const (
+	RevocationTokensRevoked      = "tokens_revoked"
+	RevocationUserDeactivated    = "user_deactivated"
+	RevocationUserDeleted        = "user_deleted"
+	RevocationUserSuspended      = "user_suspended"
+	RevocationPermissionsChanged = "permissions_changed"
+	// RevocationResync tells subscribers that events may have been lost.
+	RevocationResync = "resync"
+)
+
+type RevocationEvent struct {
+	Type   string `json:"type"`
+	UserID int64  `json:"user_id"`
+	Scope  string `json:"scope,omitempty"`
+}
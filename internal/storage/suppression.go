@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrEmailSuppressed     = errors.New("email address is suppressed")
+	ErrSuppressionNotFound = errors.New("suppression not found")
+)
+
+const (
+	SuppressionBounce    = "bounce"
+	SuppressionComplaint = "complaint"
+)
+
+type Suppression struct {
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail"`
+}
+
+type SuppressionFilter struct {
+	EmailPrefix string
+	// AfterEmail continues an alphabetical listing from the last address of
+	// the previous page.
+	AfterEmail string
+	Limit      int
+}
@@ -20,6 +20,8 @@ type Token struct {
 	Scope     string    `json:"-"`
 }
 
+func (*Token) outboxMessage() {}
+
 func GenerateToken(userID int64, ttl time.Duration, scope string) (*Token, error) {
 	token := &Token{
 		UserID: userID,
@@ -2,9 +2,8 @@ package storage
 
 import (
 	"errors"
+	"strings"
 	"time"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -13,8 +12,37 @@ var (
 	ErrEditConflict   = errors.New("user not found")
 )
 
+const PermissionUsersAdmin = "users:admin"
+
+// UnknownPermissionsError lists requested permission codes that do not exist.
+type UnknownPermissionsError struct {
+	Codes []string
+}
+
+func (e *UnknownPermissionsError) Error() string {
+	return "unknown permissions: " + strings.Join(e.Codes, ", ")
+}
+
+const DefaultLocale = "en"
+
 type Permissions []string
 
+// Include reports whether code is granted, either directly or through a
+// segment wildcard such as "movies:*", which covers "movies:read" but not
+// "moviesadmin:read".
+func (p Permissions) Include(code string) bool {
+	for _, granted := range p {
+		if granted == code {
+			return true
+		}
+		segment, ok := strings.CutSuffix(granted, ":*")
+		if ok && segment != "" && !strings.Contains(segment, ":") && strings.HasPrefix(code, segment+":") {
+			return true
+		}
+	}
+	return false
+}
+
 var AnonymousUser = &User{}
 
 type User struct {
@@ -23,35 +51,46 @@ type User struct {
 	Name         string      `json:"name" validate:"required,lte=500"`
 	Email        string      `json:"email" validate:"required,email"`
 	PasswordHash []byte      `json:"-"`
-	Password     *string     `db:"-" json:"-" validate:"required,gte=8,lte=72"`
+	Password     *string     `db:"-" json:"-" validate:"required"`
 	Activated    bool        `json:"activated"`
+	Locale       string      `json:"locale" validate:"required,bcp47_language_tag"`
 	Version      int         `json:"-"`
 	Permissions  Permissions `json:"permissions" db:"-"`
+
+	SuspendedAt     *time.Time `json:"suspended_at,omitempty"`
+	SuspendedUntil  *time.Time `json:"suspended_until,omitempty"`
+	SuspendedReason string     `json:"suspended_reason,omitempty"`
+	SuspendedBy     *int64     `json:"suspended_by,omitempty"`
+
+	// EmailUndeliverable flags accounts whose address is on the suppression
+	// list, so no email can reach them.
+	EmailUndeliverable bool `json:"email_undeliverable"`
 }
 
 func (u *User) IsAnonymous() bool {
 	return u == AnonymousUser
 }
 
-func (u *User) SetPassword(plaintextPassword string) error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(plaintextPassword), 12)
-	if err != nil {
-		return err
-	}
-	u.Password = &plaintextPassword
-	u.PasswordHash = hash
-	return nil
-}
-
-func (u *User) PasswordMatches(plaintextPassword string) (bool, error) {
-	err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(plaintextPassword))
-	if err != nil {
-		switch {
-		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
-			return false, nil
-		default:
-			return false, err
-		}
+// IsSuspended reports whether the account is suspended at the given moment.
+// Temporary suspensions stop applying once SuspendedUntil has passed.
+func (u *User) IsSuspended(now time.Time) bool {
+	if u.SuspendedAt == nil {
+		return false
 	}
-	return true, nil
+	return u.SuspendedUntil == nil || u.SuspendedUntil.After(now)
+}
+
+func (u *User) Suspend(by int64, reason string, until *time.Time) {
+	now := time.Now()
+	u.SuspendedAt = &now
+	u.SuspendedBy = &by
+	u.SuspendedReason = reason
+	u.SuspendedUntil = until
+}
+
+func (u *User) Unsuspend() {
+	u.SuspendedAt = nil
+	u.SuspendedBy = nil
+	u.SuspendedReason = ""
+	u.SuspendedUntil = nil
 }
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookUserRegistered   = "user.registered"
+	WebhookUserActivated    = "user.activated"
+	WebhookUserEmailChanged = "user.email_changed"
+	WebhookUserDeleted      = "user.deleted"
+)
+
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookDead      = "dead"
+)
+
+type WebhookDelivery struct {
+	ID            int64     `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	Subscription  string    `json:"subscription"`
+	Event         string    `json:"event"`
+	Payload       []byte    `json:"-"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error"`
+
+	user *User
+	data map[string]any
+}
+
+func NewWebhookDelivery(subscription, event string, user *User, data map[string]any) *WebhookDelivery {
+	return &WebhookDelivery{
+		Subscription: subscription,
+		Event:        event,
+		user:         user,
+		data:         data,
+	}
+}
+
+func (*WebhookDelivery) outboxMessage() {}
+
+// EncodePayload renders the webhook body. It is called when the delivery is
+// written, so that ids assigned earlier in the same transaction are included.
+func (d *WebhookDelivery) EncodePayload() error {
+	payload := struct {
+		Event      string         `json:"event"`
+		OccurredAt time.Time      `json:"occurred_at"`
+		User       *User          `json:"user"`
+		Data       map[string]any `json:"data,omitempty"`
+	}{
+		Event:      d.Event,
+		OccurredAt: time.Now().UTC(),
+		User:       d.user,
+		Data:       d.data,
+	}
+
+	var err error
+	d.Payload, err = json.Marshal(payload)
+	return err
+}
@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/config"
+	"github.com/AndreyChufelin/movies-auth/internal/queue"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+)
+
+const (
+	batchSize  = 10
+	baseDelay  = 5 * time.Second
+	maxDelay   = time.Hour
+	leaseSlack = 30 * time.Second
+)
+
+type Store interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*storage.WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, id int64) error
+	MarkWebhookFailed(ctx context.Context, id int64, lastError string, nextAttempt time.Time, dead bool) error
+}
+
+// Dispatcher turns account lifecycle events into outbox deliveries for every
+// matching subscription and sends them, signed, from a pool of workers.
+type Dispatcher struct {
+	store         Store
+	logger        *slog.Logger
+	client        *http.Client
+	subscriptions map[string]config.WebhookSubscription
+	queue         queue.Queue[*storage.WebhookDelivery]
+	retry         queue.Retry
+}
+
+func New(store Store, logger *slog.Logger, conf config.WebhooksConf) *Dispatcher {
+	d := &Dispatcher{
+		store:         store,
+		logger:        logger.With("component", "webhooks"),
+		client:        &http.Client{Timeout: conf.Timeout},
+		subscriptions: make(map[string]config.WebhookSubscription, len(conf.Subscriptions)),
+		retry: queue.Retry{
+			BaseDelay:   baseDelay,
+			MaxDelay:    maxDelay,
+			MaxAttempts: conf.MaxAttempts,
+		},
+	}
+	if d.client.Timeout <= 0 {
+		d.client.Timeout = 10 * time.Second
+	}
+	if d.retry.MaxAttempts <= 0 {
+		d.retry.MaxAttempts = 10
+	}
+
+	d.queue = queue.Queue[*storage.WebhookDelivery]{
+		Logger:       d.logger,
+		Workers:      conf.Workers,
+		BatchSize:    batchSize,
+		PollInterval: conf.PollInterval,
+		Lease:        d.client.Timeout*batchSize + leaseSlack,
+		Claim:        store.ClaimWebhookDeliveries,
+		Handle:       d.deliver,
+	}
+	if d.queue.PollInterval <= 0 {
+		d.queue.PollInterval = time.Second
+	}
+
+	for _, sub := range conf.Subscriptions {
+		d.subscriptions[sub.Name] = sub
+	}
+
+	return d
+}
+
+// Deliveries returns one outbox message per subscription listening to event.
+func (d *Dispatcher) Deliveries(event string, user *storage.User, data map[string]any) []storage.OutboxMessage {
+	var messages []storage.OutboxMessage
+	for name, sub := range d.subscriptions {
+		if slices.Contains(sub.Events, event) || slices.Contains(sub.Events, "*") {
+			messages = append(messages, storage.NewWebhookDelivery(name, event, user, data))
+		}
+	}
+	return messages
+}
+
+// Run delivers pending webhooks until ctx is done. In-flight deliveries are
+// finished before it returns.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.queue.Run(ctx)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *storage.WebhookDelivery) {
+	logg := d.logger.With("delivery_id", delivery.ID, "subscription", delivery.Subscription)
+
+	sub, ok := d.subscriptions[delivery.Subscription]
+	if !ok {
+		logg.Warn("webhook subscription no longer configured")
+		d.markFailed(ctx, logg, delivery, "subscription not configured", time.Now(), true)
+		return
+	}
+
+	err := d.send(ctx, sub, delivery)
+	if err != nil {
+		next, dead := d.retry.Next(delivery.Attempts + 1)
+		logg.Warn("webhook delivery failed", "attempt", delivery.Attempts+1, "dead", dead, "error", err)
+		d.markFailed(ctx, logg, delivery, err.Error(), next, dead)
+		return
+	}
+
+	err = d.store.MarkWebhookDelivered(ctx, delivery.ID)
+	if err != nil {
+		logg.Error("failed to mark webhook delivered", "error", err)
+	}
+}
+
+func (d *Dispatcher) markFailed(
+	ctx context.Context,
+	logg *slog.Logger,
+	delivery *storage.WebhookDelivery,
+	reason string,
+	next time.Time,
+	dead bool,
+) {
+	err := d.store.MarkWebhookFailed(ctx, delivery.ID, reason, next, dead)
+	if err != nil {
+		logg.Error("failed to mark webhook failed", "error", err)
+	}
+}
+
+func (d *Dispatcher) send(
+	ctx context.Context,
+	sub config.WebhookSubscription,
+	delivery *storage.WebhookDelivery,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign computes the hex HMAC-SHA256 of "<timestamp>.<body>". Receivers
+// recompute it with the shared secret and compare it with X-Webhook-Signature.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AndreyChufelin/movies-auth/internal/config"
+	"github.com/AndreyChufelin/movies-auth/internal/storage"
+)
+
+type failure struct {
+	id          int64
+	lastError   string
+	nextAttempt time.Time
+	dead        bool
+}
+
+type fakeStore struct {
+	mu        sync.Mutex
+	delivered []int64
+	failed    []failure
+}
+
+func (s *fakeStore) ClaimWebhookDeliveries(context.Context, int, time.Duration) ([]*storage.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) MarkWebhookDelivered(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delivered = append(s.delivered, id)
+	return nil
+}
+
+func (s *fakeStore) MarkWebhookFailed(_ context.Context, id int64, lastError string, next time.Time, dead bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failed = append(s.failed, failure{id: id, lastError: lastError, nextAttempt: next, dead: dead})
+	return nil
+}
+
+func newTestDispatcher(store Store, url string) *Dispatcher {
+	return New(store, slog.New(slog.DiscardHandler), config.WebhooksConf{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		Subscriptions: []config.WebhookSubscription{
+			{Name: "movies", URL: url, Secret: "s3cret", Events: []string{"*"}},
+		},
+	})
+}
+
+func TestDeliverSignsRequest(t *testing.T) {
+	payload := []byte(`{"event":"user.registered","user_id":7}`)
+
+	var (
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{}
+	d := newTestDispatcher(store, server.URL)
+	d.deliver(context.Background(), &storage.WebhookDelivery{
+		ID:           42,
+		Subscription: "movies",
+		Event:        storage.WebhookUserRegistered,
+		Payload:      payload,
+	})
+
+	if len(store.delivered) != 1 || store.delivered[0] != 42 {
+		t.Fatalf("delivered = %v, failed = %+v", store.delivered, store.failed)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+	if got := header.Get("X-Webhook-Id"); got != "42" {
+		t.Errorf("X-Webhook-Id = %q", got)
+	}
+	if got := header.Get("X-Webhook-Event"); got != storage.WebhookUserRegistered {
+		t.Errorf("X-Webhook-Event = %q", got)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(header.Get("X-Webhook-Timestamp") + "." + string(payload)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get("X-Webhook-Signature"); got != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", got, want)
+	}
+}
+
+func TestDeliverRetriesThenDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		subscription string
+		attempts     int
+		dead         bool
+	}{
+		{name: "first failure is retried", subscription: "movies", attempts: 0},
+		{name: "failure before the limit is retried", subscription: "movies", attempts: 1},
+		{name: "last attempt is dead-lettered", subscription: "movies", attempts: 2, dead: true},
+		{name: "unknown subscription is dead-lettered", subscription: "removed", dead: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			d := newTestDispatcher(store, server.URL)
+
+			before := time.Now()
+			d.deliver(context.Background(), &storage.WebhookDelivery{
+				ID:           1,
+				Subscription: tt.subscription,
+				Event:        storage.WebhookUserRegistered,
+				Attempts:     tt.attempts,
+				Payload:      []byte(`{}`),
+			})
+
+			if len(store.delivered) != 0 {
+				t.Fatalf("delivered = %v, want none", store.delivered)
+			}
+			if len(store.failed) != 1 {
+				t.Fatalf("failed = %+v, want one", store.failed)
+			}
+			f := store.failed[0]
+			if f.dead != tt.dead {
+				t.Errorf("dead = %v, want %v", f.dead, tt.dead)
+			}
+			if f.lastError == "" {
+				t.Error("last error is empty")
+			}
+			if !tt.dead && !f.nextAttempt.After(before) {
+				t.Errorf("next attempt %v is not after %v", f.nextAttempt, before)
+			}
+		})
+	}
+}
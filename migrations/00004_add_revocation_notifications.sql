@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_tokens_revoked() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('revocations', json_build_object(
+    'type', 'tokens_revoked', 'user_id', revoked.user_id, 'scope', revoked.scope)::text)
+  FROM (SELECT DISTINCT user_id, scope FROM old_tokens) AS revoked;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tokens_revoked
+AFTER DELETE ON tokens
+REFERENCING OLD TABLE AS old_tokens
+FOR EACH STATEMENT EXECUTE FUNCTION notify_tokens_revoked();
+
+CREATE OR REPLACE FUNCTION notify_user_changed() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('revocations', json_build_object('type', 'user_deleted', 'user_id', OLD.id)::text);
+  ELSIF OLD.activated AND NOT NEW.activated THEN
+    PERFORM pg_notify('revocations', json_build_object('type', 'user_deactivated', 'user_id', NEW.id)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_changed
+AFTER UPDATE OR DELETE ON users
+FOR EACH ROW EXECUTE FUNCTION notify_user_changed();
+
+CREATE OR REPLACE FUNCTION notify_permissions_changed() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('revocations', json_build_object(
+    'type', 'permissions_changed', 'user_id', COALESCE(NEW.user_id, OLD.user_id))::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER permissions_changed
+AFTER INSERT OR DELETE ON users_permissions
+FOR EACH ROW EXECUTE FUNCTION notify_permissions_changed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS permissions_changed ON users_permissions;
+DROP TRIGGER IF EXISTS user_changed ON users;
+DROP TRIGGER IF EXISTS tokens_revoked ON tokens;
+DROP FUNCTION IF EXISTS notify_permissions_changed();
+DROP FUNCTION IF EXISTS notify_user_changed();
+DROP FUNCTION IF EXISTS notify_tokens_revoked();
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+  ADD COLUMN suspended_at timestamp(0) with time zone,
+  ADD COLUMN suspended_until timestamp(0) with time zone,
+  ADD COLUMN suspended_reason text NOT NULL DEFAULT '',
+  ADD COLUMN suspended_by bigint REFERENCES users ON DELETE SET NULL;
+
+INSERT INTO permissions (code)
+VALUES ('users:admin');
+
+CREATE OR REPLACE FUNCTION notify_user_changed() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('revocations', json_build_object('type', 'user_deleted', 'user_id', OLD.id)::text);
+  ELSIF OLD.activated AND NOT NEW.activated THEN
+    PERFORM pg_notify('revocations', json_build_object('type', 'user_deactivated', 'user_id', NEW.id)::text);
+  ELSIF NEW.suspended_at IS NOT NULL AND OLD.suspended_at IS DISTINCT FROM NEW.suspended_at THEN
+    PERFORM pg_notify('revocations', json_build_object('type', 'user_suspended', 'user_id', NEW.id)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_user_changed() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify('revocations', json_build_object('type', 'user_deleted', 'user_id', OLD.id)::text);
+  ELSIF OLD.activated AND NOT NEW.activated THEN
+    PERFORM pg_notify('revocations', json_build_object('type', 'user_deactivated', 'user_id', NEW.id)::text);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DELETE FROM permissions WHERE code = 'users:admin';
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS suspended_by,
+  DROP COLUMN IF EXISTS suspended_reason,
+  DROP COLUMN IF EXISTS suspended_until,
+  DROP COLUMN IF EXISTS suspended_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS email_suppressions (
+  email citext PRIMARY KEY,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  reason text NOT NULL,
+  detail text NOT NULL DEFAULT ''
+);
+ALTER TABLE users ADD COLUMN email_undeliverable bool NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS email_undeliverable;
+DROP TABLE IF EXISTS email_suppressions;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+  id bigserial PRIMARY KEY,
+  created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+  created_by bigint REFERENCES users ON DELETE SET NULL,
+  name text NOT NULL,
+  hash bytea NOT NULL UNIQUE,
+  methods text[] NOT NULL,
+  expiry timestamp(0) with time zone,
+  last_used_at timestamp(0) with time zone,
+  revoked_at timestamp(0) with time zone
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Sent emails no longer keep their template data, which may hold one-time
+-- tokens. Failed ones keep it so that an operator can retry them.
+UPDATE email_outbox SET data = '{}' WHERE status = 'sent' AND data <> '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Grants every movies:<action> permission, current and future.
+INSERT INTO permissions (code)
+SELECT 'movies:*'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'movies:*');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE code = 'movies:*';
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Only authentication tokens are cached by other services; consumed
+-- activation tokens no longer wake every revocation subscriber.
+CREATE OR REPLACE FUNCTION notify_tokens_revoked() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('revocations', json_build_object(
+    'type', 'tokens_revoked', 'user_id', revoked.user_id, 'scope', revoked.scope)::text)
+  FROM (SELECT DISTINCT user_id, scope FROM old_tokens WHERE scope = 'authentication') AS revoked;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_tokens_revoked() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('revocations', json_build_object(
+    'type', 'tokens_revoked', 'user_id', revoked.user_id, 'scope', revoked.scope)::text)
+  FROM (SELECT DISTINCT user_id, scope FROM old_tokens) AS revoked;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
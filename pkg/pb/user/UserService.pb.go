@@ -9,6 +9,7 @@ package user
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,6 +22,221 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// PermissionMatch defaults to requiring every permission, so a client that
+// leaves it unset never gets the permissive check.
+type PermissionMatch int32
+
+const (
+	PermissionMatch_PERMISSION_MATCH_UNSPECIFIED PermissionMatch = 0
+	PermissionMatch_PERMISSION_MATCH_ALL         PermissionMatch = 1
+	PermissionMatch_PERMISSION_MATCH_ANY         PermissionMatch = 2
+)
+
+// Enum value maps for PermissionMatch.
+var (
+	PermissionMatch_name = map[int32]string{
+		0: "PERMISSION_MATCH_UNSPECIFIED",
+		1: "PERMISSION_MATCH_ALL",
+		2: "PERMISSION_MATCH_ANY",
+	}
+	PermissionMatch_value = map[string]int32{
+		"PERMISSION_MATCH_UNSPECIFIED": 0,
+		"PERMISSION_MATCH_ALL":         1,
+		"PERMISSION_MATCH_ANY":         2,
+	}
+)
+
+func (x PermissionMatch) Enum() *PermissionMatch {
+	p := new(PermissionMatch)
+	*p = x
+	return p
+}
+
+func (x PermissionMatch) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PermissionMatch) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_pb_UserService_proto_enumTypes[0].Descriptor()
+}
+
+func (PermissionMatch) Type() protoreflect.EnumType {
+	return &file_pkg_pb_UserService_proto_enumTypes[0]
+}
+
+func (x PermissionMatch) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PermissionMatch.Descriptor instead.
+func (PermissionMatch) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{0}
+}
+
+type RevocationType int32
+
+const (
+	RevocationType_REVOCATION_TYPE_UNSPECIFIED RevocationType = 0
+	// Authentication tokens of the user were deleted.
+	RevocationType_REVOCATION_TYPE_TOKENS_REVOKED      RevocationType = 1
+	RevocationType_REVOCATION_TYPE_USER_DEACTIVATED    RevocationType = 2
+	RevocationType_REVOCATION_TYPE_USER_DELETED        RevocationType = 3
+	RevocationType_REVOCATION_TYPE_PERMISSIONS_CHANGED RevocationType = 4
+	RevocationType_REVOCATION_TYPE_USER_SUSPENDED      RevocationType = 5
+	// Earlier events may have been missed; drop every cached authorization.
+	RevocationType_REVOCATION_TYPE_RESYNC RevocationType = 6
+)
+
+// Enum value maps for RevocationType.
+var (
+	RevocationType_name = map[int32]string{
+		0: "REVOCATION_TYPE_UNSPECIFIED",
+		1: "REVOCATION_TYPE_TOKENS_REVOKED",
+		2: "REVOCATION_TYPE_USER_DEACTIVATED",
+		3: "REVOCATION_TYPE_USER_DELETED",
+		4: "REVOCATION_TYPE_PERMISSIONS_CHANGED",
+		5: "REVOCATION_TYPE_USER_SUSPENDED",
+		6: "REVOCATION_TYPE_RESYNC",
+	}
+	RevocationType_value = map[string]int32{
+		"REVOCATION_TYPE_UNSPECIFIED":         0,
+		"REVOCATION_TYPE_TOKENS_REVOKED":      1,
+		"REVOCATION_TYPE_USER_DEACTIVATED":    2,
+		"REVOCATION_TYPE_USER_DELETED":        3,
+		"REVOCATION_TYPE_PERMISSIONS_CHANGED": 4,
+		"REVOCATION_TYPE_USER_SUSPENDED":      5,
+		"REVOCATION_TYPE_RESYNC":              6,
+	}
+)
+
+func (x RevocationType) Enum() *RevocationType {
+	p := new(RevocationType)
+	*p = x
+	return p
+}
+
+func (x RevocationType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RevocationType) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_pb_UserService_proto_enumTypes[1].Descriptor()
+}
+
+func (RevocationType) Type() protoreflect.EnumType {
+	return &file_pkg_pb_UserService_proto_enumTypes[1]
+}
+
+func (x RevocationType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RevocationType.Descriptor instead.
+func (RevocationType) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{1}
+}
+
+type UserSortField int32
+
+const (
+	UserSortField_USER_SORT_FIELD_ID         UserSortField = 0
+	UserSortField_USER_SORT_FIELD_CREATED_AT UserSortField = 1
+	UserSortField_USER_SORT_FIELD_EMAIL      UserSortField = 2
+)
+
+// Enum value maps for UserSortField.
+var (
+	UserSortField_name = map[int32]string{
+		0: "USER_SORT_FIELD_ID",
+		1: "USER_SORT_FIELD_CREATED_AT",
+		2: "USER_SORT_FIELD_EMAIL",
+	}
+	UserSortField_value = map[string]int32{
+		"USER_SORT_FIELD_ID":         0,
+		"USER_SORT_FIELD_CREATED_AT": 1,
+		"USER_SORT_FIELD_EMAIL":      2,
+	}
+)
+
+func (x UserSortField) Enum() *UserSortField {
+	p := new(UserSortField)
+	*p = x
+	return p
+}
+
+func (x UserSortField) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UserSortField) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_pb_UserService_proto_enumTypes[2].Descriptor()
+}
+
+func (UserSortField) Type() protoreflect.EnumType {
+	return &file_pkg_pb_UserService_proto_enumTypes[2]
+}
+
+func (x UserSortField) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UserSortField.Descriptor instead.
+func (UserSortField) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{2}
+}
+
+type BounceType int32
+
+const (
+	BounceType_BOUNCE_TYPE_UNSPECIFIED BounceType = 0
+	BounceType_BOUNCE_TYPE_HARD        BounceType = 1
+	BounceType_BOUNCE_TYPE_SOFT        BounceType = 2
+	BounceType_BOUNCE_TYPE_COMPLAINT   BounceType = 3
+)
+
+// Enum value maps for BounceType.
+var (
+	BounceType_name = map[int32]string{
+		0: "BOUNCE_TYPE_UNSPECIFIED",
+		1: "BOUNCE_TYPE_HARD",
+		2: "BOUNCE_TYPE_SOFT",
+		3: "BOUNCE_TYPE_COMPLAINT",
+	}
+	BounceType_value = map[string]int32{
+		"BOUNCE_TYPE_UNSPECIFIED": 0,
+		"BOUNCE_TYPE_HARD":        1,
+		"BOUNCE_TYPE_SOFT":        2,
+		"BOUNCE_TYPE_COMPLAINT":   3,
+	}
+)
+
+func (x BounceType) Enum() *BounceType {
+	p := new(BounceType)
+	*p = x
+	return p
+}
+
+func (x BounceType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BounceType) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_pb_UserService_proto_enumTypes[3].Descriptor()
+}
+
+func (BounceType) Type() protoreflect.EnumType {
+	return &file_pkg_pb_UserService_proto_enumTypes[3]
+}
+
+func (x BounceType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BounceType.Descriptor instead.
+func (BounceType) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{3}
+}
+
 type UserMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -29,6 +245,7 @@ type UserMessage struct {
 	Activated     bool                   `protobuf:"varint,4,opt,name=activated,proto3" json:"activated,omitempty"`
 	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	Permissions   []string               `protobuf:"bytes,6,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	Locale        string                 `protobuf:"bytes,7,opt,name=locale,proto3" json:"locale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -105,11 +322,19 @@ func (x *UserMessage) GetPermissions() []string {
 	return nil
 }
 
+func (x *UserMessage) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
 type RegisterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
 	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	Locale        string                 `protobuf:"bytes,4,opt,name=locale,proto3" json:"locale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -165,6 +390,13 @@ func (x *RegisterRequest) GetPassword() string {
 	return ""
 }
 
+func (x *RegisterRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
 type ActivatedRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
@@ -357,38 +589,2694 @@ func (x *VerifyTokenRequest) GetToken() string {
 	return ""
 }
 
-var File_pkg_pb_UserService_proto protoreflect.FileDescriptor
+type VerifyTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []string               `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_pkg_pb_UserService_proto_rawDesc = "" +
-	"\n" +
-	"\x18pkg/pb/UserService.proto\x12\x04user\"\xa6\x01\n" +
-	"\vUserMessage\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1c\n" +
-	"\tactivated\x18\x04 \x01(\bR\tactivated\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12 \n" +
-	"\vpermissions\x18\x06 \x03(\tR\vpermissions\"W\n" +
-	"\x0fRegisterRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
-	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
-	"\bpassword\x18\x03 \x01(\tR\bpassword\"(\n" +
-	"\x10ActivatedRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"I\n" +
-	"\x15AuthenticationRequest\x12\x14\n" +
-	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"F\n" +
-	"\x16AuthenticationResponse\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12\x16\n" +
-	"\x06expiry\x18\x02 \x01(\x03R\x06expiry\"*\n" +
-	"\x12VerifyTokenRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token2\x84\x02\n" +
-	"\vUserService\x124\n" +
-	"\bRegister\x12\x15.user.RegisterRequest\x1a\x11.user.UserMessage\x126\n" +
-	"\tActivated\x12\x16.user.ActivatedRequest\x1a\x11.user.UserMessage\x12K\n" +
-	"\x0eAuthentication\x12\x1b.user.AuthenticationRequest\x1a\x1c.user.AuthenticationResponse\x12:\n" +
-	"\vVerifyToken\x12\x18.user.VerifyTokenRequest\x1a\x11.user.UserMessageB3Z1github.com/AndreyChufelin/movies-auth/pkg/pb/userb\x06proto3"
+func (x *VerifyTokensRequest) Reset() {
+	*x = VerifyTokensRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTokensRequest) ProtoMessage() {}
+
+func (x *VerifyTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTokensRequest.ProtoReflect.Descriptor instead.
+func (*VerifyTokensRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyTokensRequest) GetTokens() []string {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type VerifyTokenResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	User          *UserMessage           `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Code          int32                  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyTokenResult) Reset() {
+	*x = VerifyTokenResult{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyTokenResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTokenResult) ProtoMessage() {}
+
+func (x *VerifyTokenResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTokenResult.ProtoReflect.Descriptor instead.
+func (*VerifyTokenResult) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerifyTokenResult) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *VerifyTokenResult) GetUser() *UserMessage {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *VerifyTokenResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *VerifyTokenResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type VerifyTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*VerifyTokenResult   `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyTokensResponse) Reset() {
+	*x = VerifyTokensResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTokensResponse) ProtoMessage() {}
+
+func (x *VerifyTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTokensResponse.ProtoReflect.Descriptor instead.
+func (*VerifyTokensResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *VerifyTokensResponse) GetResults() []*VerifyTokenResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type AuthorizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Permissions   []string               `protobuf:"bytes,2,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	Match         PermissionMatch        `protobuf:"varint,3,opt,name=match,proto3,enum=user.PermissionMatch" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthorizeRequest) Reset() {
+	*x = AuthorizeRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthorizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthorizeRequest) ProtoMessage() {}
+
+func (x *AuthorizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthorizeRequest.ProtoReflect.Descriptor instead.
+func (*AuthorizeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AuthorizeRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *AuthorizeRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *AuthorizeRequest) GetMatch() PermissionMatch {
+	if x != nil {
+		return x.Match
+	}
+	return PermissionMatch_PERMISSION_MATCH_UNSPECIFIED
+}
+
+type AuthorizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Missing       []string               `protobuf:"bytes,3,rep,name=missing,proto3" json:"missing,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthorizeResponse) Reset() {
+	*x = AuthorizeResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthorizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthorizeResponse) ProtoMessage() {}
+
+func (x *AuthorizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthorizeResponse.ProtoReflect.Descriptor instead.
+func (*AuthorizeResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AuthorizeResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *AuthorizeResponse) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AuthorizeResponse) GetMissing() []string {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+type WatchRevocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRevocationsRequest) Reset() {
+	*x = WatchRevocationsRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRevocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRevocationsRequest) ProtoMessage() {}
+
+func (x *WatchRevocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRevocationsRequest.ProtoReflect.Descriptor instead.
+func (*WatchRevocationsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{11}
+}
+
+type RevocationEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          RevocationType         `protobuf:"varint,1,opt,name=type,proto3,enum=user.RevocationType" json:"type,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Scope         string                 `protobuf:"bytes,3,opt,name=scope,proto3" json:"scope,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevocationEvent) Reset() {
+	*x = RevocationEvent{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevocationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevocationEvent) ProtoMessage() {}
+
+func (x *RevocationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevocationEvent.ProtoReflect.Descriptor instead.
+func (*RevocationEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RevocationEvent) GetType() RevocationType {
+	if x != nil {
+		return x.Type
+	}
+	return RevocationType_REVOCATION_TYPE_UNSPECIFIED
+}
+
+func (x *RevocationEvent) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RevocationEvent) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+type Suspension struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	SuspendedBy   int64                  `protobuf:"varint,2,opt,name=suspended_by,json=suspendedBy,proto3" json:"suspended_by,omitempty"`
+	SuspendedAt   int64                  `protobuf:"varint,3,opt,name=suspended_at,json=suspendedAt,proto3" json:"suspended_at,omitempty"`
+	Until         int64                  `protobuf:"varint,4,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suspension) Reset() {
+	*x = Suspension{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suspension) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suspension) ProtoMessage() {}
+
+func (x *Suspension) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suspension.ProtoReflect.Descriptor instead.
+func (*Suspension) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Suspension) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Suspension) GetSuspendedBy() int64 {
+	if x != nil {
+		return x.SuspendedBy
+	}
+	return 0
+}
+
+func (x *Suspension) GetSuspendedAt() int64 {
+	if x != nil {
+		return x.SuspendedAt
+	}
+	return 0
+}
+
+func (x *Suspension) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+type AdminUserMessage struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	User               *UserMessage           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Suspension         *Suspension            `protobuf:"bytes,2,opt,name=suspension,proto3" json:"suspension,omitempty"`
+	Suspended          bool                   `protobuf:"varint,3,opt,name=suspended,proto3" json:"suspended,omitempty"`
+	Version            int32                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	EmailUndeliverable bool                   `protobuf:"varint,5,opt,name=email_undeliverable,json=emailUndeliverable,proto3" json:"email_undeliverable,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *AdminUserMessage) Reset() {
+	*x = AdminUserMessage{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminUserMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminUserMessage) ProtoMessage() {}
+
+func (x *AdminUserMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminUserMessage.ProtoReflect.Descriptor instead.
+func (*AdminUserMessage) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AdminUserMessage) GetUser() *UserMessage {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *AdminUserMessage) GetSuspension() *Suspension {
+	if x != nil {
+		return x.Suspension
+	}
+	return nil
+}
+
+func (x *AdminUserMessage) GetSuspended() bool {
+	if x != nil {
+		return x.Suspended
+	}
+	return false
+}
+
+func (x *AdminUserMessage) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *AdminUserMessage) GetEmailUndeliverable() bool {
+	if x != nil {
+		return x.EmailUndeliverable
+	}
+	return false
+}
+
+type SuspendUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Until         int64                  `protobuf:"varint,3,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuspendUserRequest) Reset() {
+	*x = SuspendUserRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuspendUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuspendUserRequest) ProtoMessage() {}
+
+func (x *SuspendUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuspendUserRequest.ProtoReflect.Descriptor instead.
+func (*SuspendUserRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SuspendUserRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SuspendUserRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SuspendUserRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+type UnsuspendUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsuspendUserRequest) Reset() {
+	*x = UnsuspendUserRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsuspendUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsuspendUserRequest) ProtoMessage() {}
+
+func (x *UnsuspendUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsuspendUserRequest.ProtoReflect.Descriptor instead.
+func (*UnsuspendUserRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UnsuspendUserRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetUserRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ListUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EmailPrefix   string                 `protobuf:"bytes,1,opt,name=email_prefix,json=emailPrefix,proto3" json:"email_prefix,omitempty"`
+	Activated     *bool                  `protobuf:"varint,2,opt,name=activated,proto3,oneof" json:"activated,omitempty"`
+	Suspended     *bool                  `protobuf:"varint,3,opt,name=suspended,proto3,oneof" json:"suspended,omitempty"`
+	Permission    string                 `protobuf:"bytes,4,opt,name=permission,proto3" json:"permission,omitempty"`
+	CreatedAfter  int64                  `protobuf:"varint,5,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore int64                  `protobuf:"varint,6,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	Sort          UserSortField          `protobuf:"varint,7,opt,name=sort,proto3,enum=user.UserSortField" json:"sort,omitempty"`
+	Descending    bool                   `protobuf:"varint,8,opt,name=descending,proto3" json:"descending,omitempty"`
+	PageSize      int32                  `protobuf:"varint,9,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,10,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListUsersRequest) GetEmailPrefix() string {
+	if x != nil {
+		return x.EmailPrefix
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetActivated() bool {
+	if x != nil && x.Activated != nil {
+		return *x.Activated
+	}
+	return false
+}
+
+func (x *ListUsersRequest) GetSuspended() bool {
+	if x != nil && x.Suspended != nil {
+		return *x.Suspended
+	}
+	return false
+}
+
+func (x *ListUsersRequest) GetPermission() string {
+	if x != nil {
+		return x.Permission
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetCreatedAfter() int64 {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetCreatedBefore() int64 {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetSort() UserSortField {
+	if x != nil {
+		return x.Sort
+	}
+	return UserSortField_USER_SORT_FIELD_ID
+}
+
+func (x *ListUsersRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *ListUsersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*AdminUserMessage    `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListUsersResponse) GetUsers() []*AdminUserMessage {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListUsersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ChangePasswordRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Token           string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	CurrentPassword string                 `protobuf:"bytes,2,opt,name=current_password,json=currentPassword,proto3" json:"current_password,omitempty"`
+	NewPassword     string                 `protobuf:"bytes,3,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ChangePasswordRequest) Reset() {
+	*x = ChangePasswordRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordRequest) ProtoMessage() {}
+
+func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePasswordRequest.ProtoReflect.Descriptor instead.
+func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ChangePasswordRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetCurrentPassword() string {
+	if x != nil {
+		return x.CurrentPassword
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+type ChangePasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangePasswordResponse) Reset() {
+	*x = ChangePasswordResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordResponse) ProtoMessage() {}
+
+func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePasswordResponse.ProtoReflect.Descriptor instead.
+func (*ChangePasswordResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{21}
+}
+
+type ChangePermissionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Permissions   []string               `protobuf:"bytes,2,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangePermissionsRequest) Reset() {
+	*x = ChangePermissionsRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePermissionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePermissionsRequest) ProtoMessage() {}
+
+func (x *ChangePermissionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePermissionsRequest.ProtoReflect.Descriptor instead.
+func (*ChangePermissionsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ChangePermissionsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ChangePermissionsRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+type AuditEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Event         string                 `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`
+	ActorId       int64                  `protobuf:"varint,4,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	TargetUserId  int64                  `protobuf:"varint,5,opt,name=target_user_id,json=targetUserId,proto3" json:"target_user_id,omitempty"`
+	PeerIp        string                 `protobuf:"bytes,6,opt,name=peer_ip,json=peerIp,proto3" json:"peer_ip,omitempty"`
+	Method        string                 `protobuf:"bytes,7,opt,name=method,proto3" json:"method,omitempty"`
+	Outcome       string                 `protobuf:"bytes,8,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	Details       *structpb.Struct       `protobuf:"bytes,9,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *AuditEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetActorId() int64 {
+	if x != nil {
+		return x.ActorId
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetTargetUserId() int64 {
+	if x != nil {
+		return x.TargetUserId
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetPeerIp() string {
+	if x != nil {
+		return x.PeerIp
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetDetails() *structpb.Struct {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type ListAuditEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         string                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	ActorId       int64                  `protobuf:"varint,2,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	TargetUserId  int64                  `protobuf:"varint,3,opt,name=target_user_id,json=targetUserId,proto3" json:"target_user_id,omitempty"`
+	Outcome       string                 `protobuf:"bytes,4,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	CreatedAfter  int64                  `protobuf:"varint,5,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore int64                  `protobuf:"varint,6,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	PageSize      int32                  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,8,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsRequest) Reset() {
+	*x = ListAuditEventsRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsRequest) ProtoMessage() {}
+
+func (x *ListAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListAuditEventsRequest) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetActorId() int64 {
+	if x != nil {
+		return x.ActorId
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetTargetUserId() int64 {
+	if x != nil {
+		return x.TargetUserId
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetCreatedAfter() int64 {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetCreatedBefore() int64 {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListAuditEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*AuditEvent          `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsResponse) Reset() {
+	*x = ListAuditEventsResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsResponse) ProtoMessage() {}
+
+func (x *ListAuditEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListAuditEventsResponse) GetEvents() []*AuditEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ListAuditEventsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UpdateProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Email         *string                `protobuf:"bytes,3,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	Locale        *string                `protobuf:"bytes,4,opt,name=locale,proto3,oneof" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProfileRequest) Reset() {
+	*x = UpdateProfileRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfileRequest) ProtoMessage() {}
+
+func (x *UpdateProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfileRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProfileRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UpdateProfileRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetLocale() string {
+	if x != nil && x.Locale != nil {
+		return *x.Locale
+	}
+	return ""
+}
+
+type DeleteUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserRequest) Reset() {
+	*x = DeleteUserRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserRequest) ProtoMessage() {}
+
+func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteUserRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type DeleteUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserResponse) Reset() {
+	*x = DeleteUserResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserResponse) ProtoMessage() {}
+
+func (x *DeleteUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserResponse.ProtoReflect.Descriptor instead.
+func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{28}
+}
+
+type EmailMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Recipient     string                 `protobuf:"bytes,3,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Template      string                 `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts      int32                  `protobuf:"varint,6,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	NextAttemptAt int64                  `protobuf:"varint,7,opt,name=next_attempt_at,json=nextAttemptAt,proto3" json:"next_attempt_at,omitempty"`
+	LastError     string                 `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	SentAt        int64                  `protobuf:"varint,9,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	Locale        string                 `protobuf:"bytes,10,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmailMessage) Reset() {
+	*x = EmailMessage{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmailMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmailMessage) ProtoMessage() {}
+
+func (x *EmailMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmailMessage.ProtoReflect.Descriptor instead.
+func (*EmailMessage) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *EmailMessage) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *EmailMessage) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *EmailMessage) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+func (x *EmailMessage) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *EmailMessage) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *EmailMessage) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *EmailMessage) GetNextAttemptAt() int64 {
+	if x != nil {
+		return x.NextAttemptAt
+	}
+	return 0
+}
+
+func (x *EmailMessage) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *EmailMessage) GetSentAt() int64 {
+	if x != nil {
+		return x.SentAt
+	}
+	return 0
+}
+
+func (x *EmailMessage) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+type ListEmailsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEmailsRequest) Reset() {
+	*x = ListEmailsRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEmailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEmailsRequest) ProtoMessage() {}
+
+func (x *ListEmailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEmailsRequest.ProtoReflect.Descriptor instead.
+func (*ListEmailsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListEmailsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListEmailsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListEmailsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListEmailsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Emails        []*EmailMessage        `protobuf:"bytes,1,rep,name=emails,proto3" json:"emails,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEmailsResponse) Reset() {
+	*x = ListEmailsResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEmailsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEmailsResponse) ProtoMessage() {}
+
+func (x *ListEmailsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEmailsResponse.ProtoReflect.Descriptor instead.
+func (*ListEmailsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListEmailsResponse) GetEmails() []*EmailMessage {
+	if x != nil {
+		return x.Emails
+	}
+	return nil
+}
+
+func (x *ListEmailsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type RetryEmailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryEmailRequest) Reset() {
+	*x = RetryEmailRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryEmailRequest) ProtoMessage() {}
+
+func (x *RetryEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryEmailRequest.ProtoReflect.Descriptor instead.
+func (*RetryEmailRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RetryEmailRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Bounce is a provider-neutral delivery notification. Only hard bounces and
+// complaints suppress the address; soft bounces are accepted and ignored.
+type Bounce struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Type          BounceType             `protobuf:"varint,2,opt,name=type,proto3,enum=user.BounceType" json:"type,omitempty"`
+	Detail        string                 `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bounce) Reset() {
+	*x = Bounce{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bounce) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bounce) ProtoMessage() {}
+
+func (x *Bounce) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bounce.ProtoReflect.Descriptor instead.
+func (*Bounce) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Bounce) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Bounce) GetType() BounceType {
+	if x != nil {
+		return x.Type
+	}
+	return BounceType_BOUNCE_TYPE_UNSPECIFIED
+}
+
+func (x *Bounce) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ReportBouncesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bounces       []*Bounce              `protobuf:"bytes,1,rep,name=bounces,proto3" json:"bounces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportBouncesRequest) Reset() {
+	*x = ReportBouncesRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportBouncesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportBouncesRequest) ProtoMessage() {}
+
+func (x *ReportBouncesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportBouncesRequest.ProtoReflect.Descriptor instead.
+func (*ReportBouncesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ReportBouncesRequest) GetBounces() []*Bounce {
+	if x != nil {
+		return x.Bounces
+	}
+	return nil
+}
+
+type ReportBouncesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suppressed    int32                  `protobuf:"varint,1,opt,name=suppressed,proto3" json:"suppressed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportBouncesResponse) Reset() {
+	*x = ReportBouncesResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportBouncesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportBouncesResponse) ProtoMessage() {}
+
+func (x *ReportBouncesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportBouncesResponse.ProtoReflect.Descriptor instead.
+func (*ReportBouncesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ReportBouncesResponse) GetSuppressed() int32 {
+	if x != nil {
+		return x.Suppressed
+	}
+	return 0
+}
+
+type SuppressionMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Detail        string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuppressionMessage) Reset() {
+	*x = SuppressionMessage{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuppressionMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuppressionMessage) ProtoMessage() {}
+
+func (x *SuppressionMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuppressionMessage.ProtoReflect.Descriptor instead.
+func (*SuppressionMessage) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SuppressionMessage) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *SuppressionMessage) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *SuppressionMessage) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SuppressionMessage) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ListSuppressionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EmailPrefix   string                 `protobuf:"bytes,1,opt,name=email_prefix,json=emailPrefix,proto3" json:"email_prefix,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSuppressionsRequest) Reset() {
+	*x = ListSuppressionsRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSuppressionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSuppressionsRequest) ProtoMessage() {}
+
+func (x *ListSuppressionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSuppressionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSuppressionsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListSuppressionsRequest) GetEmailPrefix() string {
+	if x != nil {
+		return x.EmailPrefix
+	}
+	return ""
+}
+
+func (x *ListSuppressionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSuppressionsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListSuppressionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suppressions  []*SuppressionMessage  `protobuf:"bytes,1,rep,name=suppressions,proto3" json:"suppressions,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSuppressionsResponse) Reset() {
+	*x = ListSuppressionsResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSuppressionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSuppressionsResponse) ProtoMessage() {}
+
+func (x *ListSuppressionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSuppressionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSuppressionsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListSuppressionsResponse) GetSuppressions() []*SuppressionMessage {
+	if x != nil {
+		return x.Suppressions
+	}
+	return nil
+}
+
+func (x *ListSuppressionsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type DeleteSuppressionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSuppressionRequest) Reset() {
+	*x = DeleteSuppressionRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSuppressionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSuppressionRequest) ProtoMessage() {}
+
+func (x *DeleteSuppressionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSuppressionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSuppressionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *DeleteSuppressionRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type DeleteSuppressionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSuppressionResponse) Reset() {
+	*x = DeleteSuppressionResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSuppressionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSuppressionResponse) ProtoMessage() {}
+
+func (x *DeleteSuppressionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSuppressionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSuppressionResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{40}
+}
+
+type APIKeyMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CreatedBy     int64                  `protobuf:"varint,3,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Methods       []string               `protobuf:"bytes,5,rep,name=methods,proto3" json:"methods,omitempty"`
+	Expiry        int64                  `protobuf:"varint,6,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	LastUsedAt    int64                  `protobuf:"varint,7,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	RevokedAt     int64                  `protobuf:"varint,8,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKeyMessage) Reset() {
+	*x = APIKeyMessage{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKeyMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKeyMessage) ProtoMessage() {}
+
+func (x *APIKeyMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKeyMessage.ProtoReflect.Descriptor instead.
+func (*APIKeyMessage) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *APIKeyMessage) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *APIKeyMessage) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *APIKeyMessage) GetCreatedBy() int64 {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return 0
+}
+
+func (x *APIKeyMessage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKeyMessage) GetMethods() []string {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *APIKeyMessage) GetExpiry() int64 {
+	if x != nil {
+		return x.Expiry
+	}
+	return 0
+}
+
+func (x *APIKeyMessage) GetLastUsedAt() int64 {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return 0
+}
+
+func (x *APIKeyMessage) GetRevokedAt() int64 {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return 0
+}
+
+// CreateAPIKeyRequest names the methods the key may call, in full
+// ("/user.UserService/VerifyToken") or by short name, or "*" for all.
+// expiry is a unix timestamp; 0 means the key never expires.
+type CreateAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Methods       []string               `protobuf:"bytes,2,rep,name=methods,proto3" json:"methods,omitempty"`
+	Expiry        int64                  `protobuf:"varint,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CreateAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetMethods() []string {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyRequest) GetExpiry() int64 {
+	if x != nil {
+		return x.Expiry
+	}
+	return 0
+}
+
+// CreateAPIKeyResponse carries the only copy of the plaintext key, sent by
+// callers in the x-api-key metadata header.
+type CreateAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey        *APIKeyMessage         `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CreateAPIKeyResponse) GetApiKey() *APIKeyMessage {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type ListAPIKeysRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IncludeRevoked bool                   `protobuf:"varint,1,opt,name=include_revoked,json=includeRevoked,proto3" json:"include_revoked,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysRequest) ProtoMessage() {}
+
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ListAPIKeysRequest) GetIncludeRevoked() bool {
+	if x != nil {
+		return x.IncludeRevoked
+	}
+	return false
+}
+
+type ListAPIKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKeys       []*APIKeyMessage       `protobuf:"bytes,1,rep,name=api_keys,json=apiKeys,proto3" json:"api_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysResponse) ProtoMessage() {}
+
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ListAPIKeysResponse) GetApiKeys() []*APIKeyMessage {
+	if x != nil {
+		return x.ApiKeys
+	}
+	return nil
+}
+
+type RevokeAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyRequest) Reset() {
+	*x = RevokeAPIKeyRequest{}
+	mi := &file_pkg_pb_UserService_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyRequest) ProtoMessage() {}
+
+func (x *RevokeAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_UserService_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_UserService_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *RevokeAPIKeyRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+var File_pkg_pb_UserService_proto protoreflect.FileDescriptor
+
+const file_pkg_pb_UserService_proto_rawDesc = "" +
+	"\n" +
+	"\x18pkg/pb/UserService.proto\x12\x04user\x1a\x1cgoogle/protobuf/struct.proto\"\xbe\x01\n" +
+	"\vUserMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1c\n" +
+	"\tactivated\x18\x04 \x01(\bR\tactivated\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12 \n" +
+	"\vpermissions\x18\x06 \x03(\tR\vpermissions\x12\x16\n" +
+	"\x06locale\x18\a \x01(\tR\x06locale\"o\n" +
+	"\x0fRegisterRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\x12\x16\n" +
+	"\x06locale\x18\x04 \x01(\tR\x06locale\"(\n" +
+	"\x10ActivatedRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"I\n" +
+	"\x15AuthenticationRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"F\n" +
+	"\x16AuthenticationResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x16\n" +
+	"\x06expiry\x18\x02 \x01(\x03R\x06expiry\"*\n" +
+	"\x12VerifyTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"-\n" +
+	"\x13VerifyTokensRequest\x12\x16\n" +
+	"\x06tokens\x18\x01 \x03(\tR\x06tokens\"z\n" +
+	"\x11VerifyTokenResult\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12%\n" +
+	"\x04user\x18\x02 \x01(\v2\x11.user.UserMessageR\x04user\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"I\n" +
+	"\x14VerifyTokensResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.user.VerifyTokenResultR\aresults\"w\n" +
+	"\x10AuthorizeRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12 \n" +
+	"\vpermissions\x18\x02 \x03(\tR\vpermissions\x12+\n" +
+	"\x05match\x18\x03 \x01(\x0e2\x15.user.PermissionMatchR\x05match\"`\n" +
+	"\x11AuthorizeResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x18\n" +
+	"\amissing\x18\x03 \x03(\tR\amissing\"\x19\n" +
+	"\x17WatchRevocationsRequest\"j\n" +
+	"\x0fRevocationEvent\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.user.RevocationTypeR\x04type\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05scope\x18\x03 \x01(\tR\x05scope\"\x80\x01\n" +
+	"\n" +
+	"Suspension\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12!\n" +
+	"\fsuspended_by\x18\x02 \x01(\x03R\vsuspendedBy\x12!\n" +
+	"\fsuspended_at\x18\x03 \x01(\x03R\vsuspendedAt\x12\x14\n" +
+	"\x05until\x18\x04 \x01(\x03R\x05until\"\xd4\x01\n" +
+	"\x10AdminUserMessage\x12%\n" +
+	"\x04user\x18\x01 \x01(\v2\x11.user.UserMessageR\x04user\x120\n" +
+	"\n" +
+	"suspension\x18\x02 \x01(\v2\x10.user.SuspensionR\n" +
+	"suspension\x12\x1c\n" +
+	"\tsuspended\x18\x03 \x01(\bR\tsuspended\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x05R\aversion\x12/\n" +
+	"\x13email_undeliverable\x18\x05 \x01(\bR\x12emailUndeliverable\"[\n" +
+	"\x12SuspendUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05until\x18\x03 \x01(\x03R\x05until\"/\n" +
+	"\x14UnsuspendUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\")\n" +
+	"\x0eGetUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\x88\x03\n" +
+	"\x10ListUsersRequest\x12!\n" +
+	"\femail_prefix\x18\x01 \x01(\tR\vemailPrefix\x12!\n" +
+	"\tactivated\x18\x02 \x01(\bH\x00R\tactivated\x88\x01\x01\x12!\n" +
+	"\tsuspended\x18\x03 \x01(\bH\x01R\tsuspended\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"permission\x18\x04 \x01(\tR\n" +
+	"permission\x12#\n" +
+	"\rcreated_after\x18\x05 \x01(\x03R\fcreatedAfter\x12%\n" +
+	"\x0ecreated_before\x18\x06 \x01(\x03R\rcreatedBefore\x12'\n" +
+	"\x04sort\x18\a \x01(\x0e2\x13.user.UserSortFieldR\x04sort\x12\x1e\n" +
+	"\n" +
+	"descending\x18\b \x01(\bR\n" +
+	"descending\x12\x1b\n" +
+	"\tpage_size\x18\t \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\n" +
+	" \x01(\tR\tpageTokenB\f\n" +
+	"\n" +
+	"_activatedB\f\n" +
+	"\n" +
+	"_suspended\"i\n" +
+	"\x11ListUsersResponse\x12,\n" +
+	"\x05users\x18\x01 \x03(\v2\x16.user.AdminUserMessageR\x05users\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"{\n" +
+	"\x15ChangePasswordRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12)\n" +
+	"\x10current_password\x18\x02 \x01(\tR\x0fcurrentPassword\x12!\n" +
+	"\fnew_password\x18\x03 \x01(\tR\vnewPassword\"\x18\n" +
+	"\x16ChangePasswordResponse\"U\n" +
+	"\x18ChangePermissionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12 \n" +
+	"\vpermissions\x18\x02 \x03(\tR\vpermissions\"\x90\x02\n" +
+	"\n" +
+	"AuditEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12\x14\n" +
+	"\x05event\x18\x03 \x01(\tR\x05event\x12\x19\n" +
+	"\bactor_id\x18\x04 \x01(\x03R\aactorId\x12$\n" +
+	"\x0etarget_user_id\x18\x05 \x01(\x03R\ftargetUserId\x12\x17\n" +
+	"\apeer_ip\x18\x06 \x01(\tR\x06peerIp\x12\x16\n" +
+	"\x06method\x18\a \x01(\tR\x06method\x12\x18\n" +
+	"\aoutcome\x18\b \x01(\tR\aoutcome\x121\n" +
+	"\adetails\x18\t \x01(\v2\x17.google.protobuf.StructR\adetails\"\x91\x02\n" +
+	"\x16ListAuditEventsRequest\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x12\x19\n" +
+	"\bactor_id\x18\x02 \x01(\x03R\aactorId\x12$\n" +
+	"\x0etarget_user_id\x18\x03 \x01(\x03R\ftargetUserId\x12\x18\n" +
+	"\aoutcome\x18\x04 \x01(\tR\aoutcome\x12#\n" +
+	"\rcreated_after\x18\x05 \x01(\x03R\fcreatedAfter\x12%\n" +
+	"\x0ecreated_before\x18\x06 \x01(\x03R\rcreatedBefore\x12\x1b\n" +
+	"\tpage_size\x18\a \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\b \x01(\tR\tpageToken\"k\n" +
+	"\x17ListAuditEventsResponse\x12(\n" +
+	"\x06events\x18\x01 \x03(\v2\x10.user.AuditEventR\x06events\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x9b\x01\n" +
+	"\x14UpdateProfileRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
+	"\x05email\x18\x03 \x01(\tH\x01R\x05email\x88\x01\x01\x12\x1b\n" +
+	"\x06locale\x18\x04 \x01(\tH\x02R\x06locale\x88\x01\x01B\a\n" +
+	"\x05_nameB\b\n" +
+	"\x06_emailB\t\n" +
+	"\a_locale\",\n" +
+	"\x11DeleteUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\x14\n" +
+	"\x12DeleteUserResponse\"\xa3\x02\n" +
+	"\fEmailMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12\x1c\n" +
+	"\trecipient\x18\x03 \x01(\tR\trecipient\x12\x1a\n" +
+	"\btemplate\x18\x04 \x01(\tR\btemplate\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1a\n" +
+	"\battempts\x18\x06 \x01(\x05R\battempts\x12&\n" +
+	"\x0fnext_attempt_at\x18\a \x01(\x03R\rnextAttemptAt\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\b \x01(\tR\tlastError\x12\x17\n" +
+	"\asent_at\x18\t \x01(\x03R\x06sentAt\x12\x16\n" +
+	"\x06locale\x18\n" +
+	" \x01(\tR\x06locale\"g\n" +
+	"\x11ListEmailsRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"h\n" +
+	"\x12ListEmailsResponse\x12*\n" +
+	"\x06emails\x18\x01 \x03(\v2\x12.user.EmailMessageR\x06emails\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"#\n" +
+	"\x11RetryEmailRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\\\n" +
+	"\x06Bounce\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12$\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x10.user.BounceTypeR\x04type\x12\x16\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detail\">\n" +
+	"\x14ReportBouncesRequest\x12&\n" +
+	"\abounces\x18\x01 \x03(\v2\f.user.BounceR\abounces\"7\n" +
+	"\x15ReportBouncesResponse\x12\x1e\n" +
+	"\n" +
+	"suppressed\x18\x01 \x01(\x05R\n" +
+	"suppressed\"y\n" +
+	"\x12SuppressionMessage\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\"x\n" +
+	"\x17ListSuppressionsRequest\x12!\n" +
+	"\femail_prefix\x18\x01 \x01(\tR\vemailPrefix\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\x80\x01\n" +
+	"\x18ListSuppressionsResponse\x12<\n" +
+	"\fsuppressions\x18\x01 \x03(\v2\x18.user.SuppressionMessageR\fsuppressions\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"0\n" +
+	"\x18DeleteSuppressionRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"\x1b\n" +
+	"\x19DeleteSuppressionResponse\"\xe4\x01\n" +
+	"\rAPIKeyMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x03 \x01(\x03R\tcreatedBy\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x18\n" +
+	"\amethods\x18\x05 \x03(\tR\amethods\x12\x16\n" +
+	"\x06expiry\x18\x06 \x01(\x03R\x06expiry\x12 \n" +
+	"\flast_used_at\x18\a \x01(\x03R\n" +
+	"lastUsedAt\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\b \x01(\x03R\trevokedAt\"[\n" +
+	"\x13CreateAPIKeyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\amethods\x18\x02 \x03(\tR\amethods\x12\x16\n" +
+	"\x06expiry\x18\x03 \x01(\x03R\x06expiry\"V\n" +
+	"\x14CreateAPIKeyResponse\x12,\n" +
+	"\aapi_key\x18\x01 \x01(\v2\x13.user.APIKeyMessageR\x06apiKey\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"=\n" +
+	"\x12ListAPIKeysRequest\x12'\n" +
+	"\x0finclude_revoked\x18\x01 \x01(\bR\x0eincludeRevoked\"E\n" +
+	"\x13ListAPIKeysResponse\x12.\n" +
+	"\bapi_keys\x18\x01 \x03(\v2\x13.user.APIKeyMessageR\aapiKeys\"%\n" +
+	"\x13RevokeAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id*g\n" +
+	"\x0fPermissionMatch\x12 \n" +
+	"\x1cPERMISSION_MATCH_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14PERMISSION_MATCH_ALL\x10\x01\x12\x18\n" +
+	"\x14PERMISSION_MATCH_ANY\x10\x02*\x86\x02\n" +
+	"\x0eRevocationType\x12\x1f\n" +
+	"\x1bREVOCATION_TYPE_UNSPECIFIED\x10\x00\x12\"\n" +
+	"\x1eREVOCATION_TYPE_TOKENS_REVOKED\x10\x01\x12$\n" +
+	" REVOCATION_TYPE_USER_DEACTIVATED\x10\x02\x12 \n" +
+	"\x1cREVOCATION_TYPE_USER_DELETED\x10\x03\x12'\n" +
+	"#REVOCATION_TYPE_PERMISSIONS_CHANGED\x10\x04\x12\"\n" +
+	"\x1eREVOCATION_TYPE_USER_SUSPENDED\x10\x05\x12\x1a\n" +
+	"\x16REVOCATION_TYPE_RESYNC\x10\x06*b\n" +
+	"\rUserSortField\x12\x16\n" +
+	"\x12USER_SORT_FIELD_ID\x10\x00\x12\x1e\n" +
+	"\x1aUSER_SORT_FIELD_CREATED_AT\x10\x01\x12\x19\n" +
+	"\x15USER_SORT_FIELD_EMAIL\x10\x02*p\n" +
+	"\n" +
+	"BounceType\x12\x1b\n" +
+	"\x17BOUNCE_TYPE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10BOUNCE_TYPE_HARD\x10\x01\x12\x14\n" +
+	"\x10BOUNCE_TYPE_SOFT\x10\x02\x12\x19\n" +
+	"\x15BOUNCE_TYPE_COMPLAINT\x10\x032\xc3\r\n" +
+	"\vUserService\x124\n" +
+	"\bRegister\x12\x15.user.RegisterRequest\x1a\x11.user.UserMessage\x126\n" +
+	"\tActivated\x12\x16.user.ActivatedRequest\x1a\x11.user.UserMessage\x12K\n" +
+	"\x0eAuthentication\x12\x1b.user.AuthenticationRequest\x1a\x1c.user.AuthenticationResponse\x12:\n" +
+	"\vVerifyToken\x12\x18.user.VerifyTokenRequest\x1a\x11.user.UserMessage\x12E\n" +
+	"\fVerifyTokens\x12\x19.user.VerifyTokensRequest\x1a\x1a.user.VerifyTokensResponse\x12<\n" +
+	"\tAuthorize\x12\x16.user.AuthorizeRequest\x1a\x17.user.AuthorizeResponse\x12K\n" +
+	"\x0eChangePassword\x12\x1b.user.ChangePasswordRequest\x1a\x1c.user.ChangePasswordResponse\x12>\n" +
+	"\rUpdateProfile\x12\x1a.user.UpdateProfileRequest\x1a\x11.user.UserMessage\x12J\n" +
+	"\x10WatchRevocations\x12\x1d.user.WatchRevocationsRequest\x1a\x15.user.RevocationEvent0\x01\x12?\n" +
+	"\vSuspendUser\x12\x18.user.SuspendUserRequest\x1a\x16.user.AdminUserMessage\x12C\n" +
+	"\rUnsuspendUser\x12\x1a.user.UnsuspendUserRequest\x1a\x16.user.AdminUserMessage\x127\n" +
+	"\aGetUser\x12\x14.user.GetUserRequest\x1a\x16.user.AdminUserMessage\x12<\n" +
+	"\tListUsers\x12\x16.user.ListUsersRequest\x1a\x17.user.ListUsersResponse\x12?\n" +
+	"\n" +
+	"DeleteUser\x12\x17.user.DeleteUserRequest\x1a\x18.user.DeleteUserResponse\x12?\n" +
+	"\n" +
+	"ListEmails\x12\x17.user.ListEmailsRequest\x1a\x18.user.ListEmailsResponse\x129\n" +
+	"\n" +
+	"RetryEmail\x12\x17.user.RetryEmailRequest\x1a\x12.user.EmailMessage\x12H\n" +
+	"\rReportBounces\x12\x1a.user.ReportBouncesRequest\x1a\x1b.user.ReportBouncesResponse\x12Q\n" +
+	"\x10ListSuppressions\x12\x1d.user.ListSuppressionsRequest\x1a\x1e.user.ListSuppressionsResponse\x12T\n" +
+	"\x11DeleteSuppression\x12\x1e.user.DeleteSuppressionRequest\x1a\x1f.user.DeleteSuppressionResponse\x12J\n" +
+	"\x10GrantPermissions\x12\x1e.user.ChangePermissionsRequest\x1a\x16.user.AdminUserMessage\x12K\n" +
+	"\x11RevokePermissions\x12\x1e.user.ChangePermissionsRequest\x1a\x16.user.AdminUserMessage\x12N\n" +
+	"\x0fListAuditEvents\x12\x1c.user.ListAuditEventsRequest\x1a\x1d.user.ListAuditEventsResponse\x12E\n" +
+	"\fCreateAPIKey\x12\x19.user.CreateAPIKeyRequest\x1a\x1a.user.CreateAPIKeyResponse\x12B\n" +
+	"\vListAPIKeys\x12\x18.user.ListAPIKeysRequest\x1a\x19.user.ListAPIKeysResponse\x12>\n" +
+	"\fRevokeAPIKey\x12\x19.user.RevokeAPIKeyRequest\x1a\x13.user.APIKeyMessageB3Z1github.com/AndreyChufelin/movies-auth/pkg/pb/userb\x06proto3"
 
 var (
 	file_pkg_pb_UserService_proto_rawDescOnce sync.Once
@@ -402,29 +3290,134 @@ func file_pkg_pb_UserService_proto_rawDescGZIP() []byte {
 	return file_pkg_pb_UserService_proto_rawDescData
 }
 
-var file_pkg_pb_UserService_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_pkg_pb_UserService_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_pkg_pb_UserService_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_pkg_pb_UserService_proto_goTypes = []any{
-	(*UserMessage)(nil),            // 0: user.UserMessage
-	(*RegisterRequest)(nil),        // 1: user.RegisterRequest
-	(*ActivatedRequest)(nil),       // 2: user.ActivatedRequest
-	(*AuthenticationRequest)(nil),  // 3: user.AuthenticationRequest
-	(*AuthenticationResponse)(nil), // 4: user.AuthenticationResponse
-	(*VerifyTokenRequest)(nil),     // 5: user.VerifyTokenRequest
+	(PermissionMatch)(0),              // 0: user.PermissionMatch
+	(RevocationType)(0),               // 1: user.RevocationType
+	(UserSortField)(0),                // 2: user.UserSortField
+	(BounceType)(0),                   // 3: user.BounceType
+	(*UserMessage)(nil),               // 4: user.UserMessage
+	(*RegisterRequest)(nil),           // 5: user.RegisterRequest
+	(*ActivatedRequest)(nil),          // 6: user.ActivatedRequest
+	(*AuthenticationRequest)(nil),     // 7: user.AuthenticationRequest
+	(*AuthenticationResponse)(nil),    // 8: user.AuthenticationResponse
+	(*VerifyTokenRequest)(nil),        // 9: user.VerifyTokenRequest
+	(*VerifyTokensRequest)(nil),       // 10: user.VerifyTokensRequest
+	(*VerifyTokenResult)(nil),         // 11: user.VerifyTokenResult
+	(*VerifyTokensResponse)(nil),      // 12: user.VerifyTokensResponse
+	(*AuthorizeRequest)(nil),          // 13: user.AuthorizeRequest
+	(*AuthorizeResponse)(nil),         // 14: user.AuthorizeResponse
+	(*WatchRevocationsRequest)(nil),   // 15: user.WatchRevocationsRequest
+	(*RevocationEvent)(nil),           // 16: user.RevocationEvent
+	(*Suspension)(nil),                // 17: user.Suspension
+	(*AdminUserMessage)(nil),          // 18: user.AdminUserMessage
+	(*SuspendUserRequest)(nil),        // 19: user.SuspendUserRequest
+	(*UnsuspendUserRequest)(nil),      // 20: user.UnsuspendUserRequest
+	(*GetUserRequest)(nil),            // 21: user.GetUserRequest
+	(*ListUsersRequest)(nil),          // 22: user.ListUsersRequest
+	(*ListUsersResponse)(nil),         // 23: user.ListUsersResponse
+	(*ChangePasswordRequest)(nil),     // 24: user.ChangePasswordRequest
+	(*ChangePasswordResponse)(nil),    // 25: user.ChangePasswordResponse
+	(*ChangePermissionsRequest)(nil),  // 26: user.ChangePermissionsRequest
+	(*AuditEvent)(nil),                // 27: user.AuditEvent
+	(*ListAuditEventsRequest)(nil),    // 28: user.ListAuditEventsRequest
+	(*ListAuditEventsResponse)(nil),   // 29: user.ListAuditEventsResponse
+	(*UpdateProfileRequest)(nil),      // 30: user.UpdateProfileRequest
+	(*DeleteUserRequest)(nil),         // 31: user.DeleteUserRequest
+	(*DeleteUserResponse)(nil),        // 32: user.DeleteUserResponse
+	(*EmailMessage)(nil),              // 33: user.EmailMessage
+	(*ListEmailsRequest)(nil),         // 34: user.ListEmailsRequest
+	(*ListEmailsResponse)(nil),        // 35: user.ListEmailsResponse
+	(*RetryEmailRequest)(nil),         // 36: user.RetryEmailRequest
+	(*Bounce)(nil),                    // 37: user.Bounce
+	(*ReportBouncesRequest)(nil),      // 38: user.ReportBouncesRequest
+	(*ReportBouncesResponse)(nil),     // 39: user.ReportBouncesResponse
+	(*SuppressionMessage)(nil),        // 40: user.SuppressionMessage
+	(*ListSuppressionsRequest)(nil),   // 41: user.ListSuppressionsRequest
+	(*ListSuppressionsResponse)(nil),  // 42: user.ListSuppressionsResponse
+	(*DeleteSuppressionRequest)(nil),  // 43: user.DeleteSuppressionRequest
+	(*DeleteSuppressionResponse)(nil), // 44: user.DeleteSuppressionResponse
+	(*APIKeyMessage)(nil),             // 45: user.APIKeyMessage
+	(*CreateAPIKeyRequest)(nil),       // 46: user.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),      // 47: user.CreateAPIKeyResponse
+	(*ListAPIKeysRequest)(nil),        // 48: user.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),       // 49: user.ListAPIKeysResponse
+	(*RevokeAPIKeyRequest)(nil),       // 50: user.RevokeAPIKeyRequest
+	(*structpb.Struct)(nil),           // 51: google.protobuf.Struct
 }
 var file_pkg_pb_UserService_proto_depIdxs = []int32{
-	1, // 0: user.UserService.Register:input_type -> user.RegisterRequest
-	2, // 1: user.UserService.Activated:input_type -> user.ActivatedRequest
-	3, // 2: user.UserService.Authentication:input_type -> user.AuthenticationRequest
-	5, // 3: user.UserService.VerifyToken:input_type -> user.VerifyTokenRequest
-	0, // 4: user.UserService.Register:output_type -> user.UserMessage
-	0, // 5: user.UserService.Activated:output_type -> user.UserMessage
-	4, // 6: user.UserService.Authentication:output_type -> user.AuthenticationResponse
-	0, // 7: user.UserService.VerifyToken:output_type -> user.UserMessage
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	4,  // 0: user.VerifyTokenResult.user:type_name -> user.UserMessage
+	11, // 1: user.VerifyTokensResponse.results:type_name -> user.VerifyTokenResult
+	0,  // 2: user.AuthorizeRequest.match:type_name -> user.PermissionMatch
+	1,  // 3: user.RevocationEvent.type:type_name -> user.RevocationType
+	4,  // 4: user.AdminUserMessage.user:type_name -> user.UserMessage
+	17, // 5: user.AdminUserMessage.suspension:type_name -> user.Suspension
+	2,  // 6: user.ListUsersRequest.sort:type_name -> user.UserSortField
+	18, // 7: user.ListUsersResponse.users:type_name -> user.AdminUserMessage
+	51, // 8: user.AuditEvent.details:type_name -> google.protobuf.Struct
+	27, // 9: user.ListAuditEventsResponse.events:type_name -> user.AuditEvent
+	33, // 10: user.ListEmailsResponse.emails:type_name -> user.EmailMessage
+	3,  // 11: user.Bounce.type:type_name -> user.BounceType
+	37, // 12: user.ReportBouncesRequest.bounces:type_name -> user.Bounce
+	40, // 13: user.ListSuppressionsResponse.suppressions:type_name -> user.SuppressionMessage
+	45, // 14: user.CreateAPIKeyResponse.api_key:type_name -> user.APIKeyMessage
+	45, // 15: user.ListAPIKeysResponse.api_keys:type_name -> user.APIKeyMessage
+	5,  // 16: user.UserService.Register:input_type -> user.RegisterRequest
+	6,  // 17: user.UserService.Activated:input_type -> user.ActivatedRequest
+	7,  // 18: user.UserService.Authentication:input_type -> user.AuthenticationRequest
+	9,  // 19: user.UserService.VerifyToken:input_type -> user.VerifyTokenRequest
+	10, // 20: user.UserService.VerifyTokens:input_type -> user.VerifyTokensRequest
+	13, // 21: user.UserService.Authorize:input_type -> user.AuthorizeRequest
+	24, // 22: user.UserService.ChangePassword:input_type -> user.ChangePasswordRequest
+	30, // 23: user.UserService.UpdateProfile:input_type -> user.UpdateProfileRequest
+	15, // 24: user.UserService.WatchRevocations:input_type -> user.WatchRevocationsRequest
+	19, // 25: user.UserService.SuspendUser:input_type -> user.SuspendUserRequest
+	20, // 26: user.UserService.UnsuspendUser:input_type -> user.UnsuspendUserRequest
+	21, // 27: user.UserService.GetUser:input_type -> user.GetUserRequest
+	22, // 28: user.UserService.ListUsers:input_type -> user.ListUsersRequest
+	31, // 29: user.UserService.DeleteUser:input_type -> user.DeleteUserRequest
+	34, // 30: user.UserService.ListEmails:input_type -> user.ListEmailsRequest
+	36, // 31: user.UserService.RetryEmail:input_type -> user.RetryEmailRequest
+	38, // 32: user.UserService.ReportBounces:input_type -> user.ReportBouncesRequest
+	41, // 33: user.UserService.ListSuppressions:input_type -> user.ListSuppressionsRequest
+	43, // 34: user.UserService.DeleteSuppression:input_type -> user.DeleteSuppressionRequest
+	26, // 35: user.UserService.GrantPermissions:input_type -> user.ChangePermissionsRequest
+	26, // 36: user.UserService.RevokePermissions:input_type -> user.ChangePermissionsRequest
+	28, // 37: user.UserService.ListAuditEvents:input_type -> user.ListAuditEventsRequest
+	46, // 38: user.UserService.CreateAPIKey:input_type -> user.CreateAPIKeyRequest
+	48, // 39: user.UserService.ListAPIKeys:input_type -> user.ListAPIKeysRequest
+	50, // 40: user.UserService.RevokeAPIKey:input_type -> user.RevokeAPIKeyRequest
+	4,  // 41: user.UserService.Register:output_type -> user.UserMessage
+	4,  // 42: user.UserService.Activated:output_type -> user.UserMessage
+	8,  // 43: user.UserService.Authentication:output_type -> user.AuthenticationResponse
+	4,  // 44: user.UserService.VerifyToken:output_type -> user.UserMessage
+	12, // 45: user.UserService.VerifyTokens:output_type -> user.VerifyTokensResponse
+	14, // 46: user.UserService.Authorize:output_type -> user.AuthorizeResponse
+	25, // 47: user.UserService.ChangePassword:output_type -> user.ChangePasswordResponse
+	4,  // 48: user.UserService.UpdateProfile:output_type -> user.UserMessage
+	16, // 49: user.UserService.WatchRevocations:output_type -> user.RevocationEvent
+	18, // 50: user.UserService.SuspendUser:output_type -> user.AdminUserMessage
+	18, // 51: user.UserService.UnsuspendUser:output_type -> user.AdminUserMessage
+	18, // 52: user.UserService.GetUser:output_type -> user.AdminUserMessage
+	23, // 53: user.UserService.ListUsers:output_type -> user.ListUsersResponse
+	32, // 54: user.UserService.DeleteUser:output_type -> user.DeleteUserResponse
+	35, // 55: user.UserService.ListEmails:output_type -> user.ListEmailsResponse
+	33, // 56: user.UserService.RetryEmail:output_type -> user.EmailMessage
+	39, // 57: user.UserService.ReportBounces:output_type -> user.ReportBouncesResponse
+	42, // 58: user.UserService.ListSuppressions:output_type -> user.ListSuppressionsResponse
+	44, // 59: user.UserService.DeleteSuppression:output_type -> user.DeleteSuppressionResponse
+	18, // 60: user.UserService.GrantPermissions:output_type -> user.AdminUserMessage
+	18, // 61: user.UserService.RevokePermissions:output_type -> user.AdminUserMessage
+	29, // 62: user.UserService.ListAuditEvents:output_type -> user.ListAuditEventsResponse
+	47, // 63: user.UserService.CreateAPIKey:output_type -> user.CreateAPIKeyResponse
+	49, // 64: user.UserService.ListAPIKeys:output_type -> user.ListAPIKeysResponse
+	45, // 65: user.UserService.RevokeAPIKey:output_type -> user.APIKeyMessage
+	41, // [41:66] is the sub-list for method output_type
+	16, // [16:41] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_pkg_pb_UserService_proto_init() }
@@ -432,18 +3425,21 @@ func file_pkg_pb_UserService_proto_init() {
 	if File_pkg_pb_UserService_proto != nil {
 		return
 	}
+	file_pkg_pb_UserService_proto_msgTypes[18].OneofWrappers = []any{}
+	file_pkg_pb_UserService_proto_msgTypes[26].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_pb_UserService_proto_rawDesc), len(file_pkg_pb_UserService_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      4,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_pkg_pb_UserService_proto_goTypes,
 		DependencyIndexes: file_pkg_pb_UserService_proto_depIdxs,
+		EnumInfos:         file_pkg_pb_UserService_proto_enumTypes,
 		MessageInfos:      file_pkg_pb_UserService_proto_msgTypes,
 	}.Build()
 	File_pkg_pb_UserService_proto = out.File